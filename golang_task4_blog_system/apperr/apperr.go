@@ -0,0 +1,159 @@
+package apperr
+
+import (
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kind 错误类别，决定 HTTP 状态码
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindConflict
+	KindTooManyRequests
+)
+
+// Error 领域错误。Code 是稳定的机器可读错误码，客户端应据此判断错误类型；
+// Message 是展示给用户的说明，Detail、Fields 和 Meta 提供补充信息，Err 为原始错误，只记录日志不返回给客户端；
+// RetryAfter 非零时响应带 Retry-After 头
+type Error struct {
+	Kind       Kind
+	Code       string
+	Message    string
+	Detail     string
+	Fields     []FieldError
+	Meta       map[string]interface{}
+	RetryAfter time.Duration
+	Err        error
+}
+
+// FieldError 单个字段的校验错误，Field 为 JSON 字段名，Code 为校验规则（如 required、email、taken）
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Code + ": " + e.Message
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	if e.Err != nil && e.Err.Error() != e.Detail {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status 错误对应的 HTTP 状态码
+func (e *Error) Status() int {
+	switch e.Kind {
+	case KindValidation:
+		return http.StatusBadRequest
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// WithDetail 设置补充说明
+func (e *Error) WithDetail(detail string) *Error {
+	e.Detail = detail
+	return e
+}
+
+// WithFields 追加字段错误
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+// WithMeta 附加随错误返回的数据，如不存在的评论ID列表
+func (e *Error) WithMeta(key string, value interface{}) *Error {
+	if e.Meta == nil {
+		e.Meta = make(map[string]interface{})
+	}
+	e.Meta[key] = value
+	return e
+}
+
+// Validation 请求参数或业务规则校验失败（400）
+func Validation(code, message string) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message}
+}
+
+// Unauthorized 未认证或认证失败（401）
+func Unauthorized(code, message string) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message}
+}
+
+// Forbidden 已认证但无权操作（403）
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// NotFound 资源不存在或对当前用户不可见（404）
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict 与已有数据冲突，如用户名已被占用（409）
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// TooManyRequests 请求过于频繁或账户被临时锁定（429），retryAfter 后可以重试
+func TooManyRequests(code, message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindTooManyRequests, Code: code, Message: message, RetryAfter: retryAfter}
+}
+
+// Internal 服务器内部错误（500），err 只记录日志
+func Internal(message string, err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: message, Err: err}
+}
+
+// InvalidField 单个请求字段不满足业务规则，如标签过长
+func InvalidField(field, code, message string) *Error {
+	return Validation(CodeValidationFailed, "输入验证失败").
+		WithDetail(message).
+		WithFields(Field(field, code, message))
+}
+
+// InvalidQuery 查询参数无效，detail 说明具体原因
+func InvalidQuery(detail string) *Error {
+	return Validation(CodeInvalidQuery, "查询参数无效").WithDetail(detail)
+}
+
+// As 返回 err 链中的 *Error；不是领域错误时包装为内部错误
+func As(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return Internal("服务器内部错误", err)
+}
+
+// IsKind 判断 err 是否为指定类别的领域错误
+func IsKind(err error, kind Kind) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Kind == kind
+}
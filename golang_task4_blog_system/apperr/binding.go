@@ -0,0 +1,84 @@
+package apperr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// 校验错误中使用 JSON 字段名，与客户端提交的字段一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return f.Name
+			}
+			return name
+		})
+	}
+}
+
+// FromBinding 把 ShouldBindJSON 等绑定请求时返回的错误转换为校验错误，
+// 字段校验失败和字段类型错误会列在 Fields 中
+func FromBinding(err error) *Error {
+	e := Validation(CodeValidationFailed, "输入验证失败").WithDetail(err.Error())
+
+	var verrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &verrs):
+		for _, fe := range verrs {
+			e.Fields = append(e.Fields, FieldError{
+				Field:   fe.Field(),
+				Code:    fe.Tag(),
+				Message: fieldMessage(fe),
+			})
+		}
+	case errors.As(err, &typeErr):
+		e.Fields = append(e.Fields, FieldError{
+			Field:   typeErr.Field,
+			Code:    "type",
+			Message: fmt.Sprintf("%s 的类型应为 %s", typeErr.Field, typeErr.Type),
+		})
+	}
+	e.Err = err
+	return e
+}
+
+// Field 构造单个字段错误
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+func fieldMessage(fe validator.FieldError) string {
+	lengthy := fe.Kind() == reflect.String || fe.Kind() == reflect.Slice || fe.Kind() == reflect.Map
+	switch fe.Tag() {
+	case "required":
+		return fe.Field() + " 不能为空"
+	case "email":
+		return fe.Field() + " 不是有效的邮箱地址"
+	case "min":
+		if lengthy {
+			return fmt.Sprintf("%s 长度不能少于 %s", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s 不能小于 %s", fe.Field(), fe.Param())
+	case "max":
+		if lengthy {
+			return fmt.Sprintf("%s 长度不能超过 %s", fe.Field(), fe.Param())
+		}
+		return fmt.Sprintf("%s 不能大于 %s", fe.Field(), fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s 必须是 %s 之一", fe.Field(), strings.ReplaceAll(fe.Param(), " ", "、"))
+	default:
+		return fmt.Sprintf("%s 不满足 %s 校验规则", fe.Field(), fe.Tag())
+	}
+}
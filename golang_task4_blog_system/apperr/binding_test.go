@@ -0,0 +1,69 @@
+package apperr
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+type bindingRequest struct {
+	UserName string   `json:"user_name" binding:"required,min=3"`
+	Email    string   `json:"email" binding:"required,email"`
+	Age      int      `json:"age" binding:"omitempty,max=150"`
+	Status   string   `json:"status,omitempty" binding:"omitempty,oneof=draft published"`
+	Tags     []string `json:"tags" binding:"max=2"`
+	Internal string   `json:"-" binding:"-"`
+}
+
+func bind(body string) error {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	var v bindingRequest
+	return binding.JSON.Bind(req, &v)
+}
+
+func TestFromBinding(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []FieldError
+	}{
+		{"missing required", `{}`, []FieldError{
+			{Field: "user_name", Code: "required", Message: "user_name 不能为空"},
+			{Field: "email", Code: "required", Message: "email 不能为空"},
+		}},
+		{"rules named by json field", `{"user_name":"al","email":"nope","age":200,"status":"gone","tags":["a","b","c"]}`, []FieldError{
+			{Field: "user_name", Code: "min", Message: "user_name 长度不能少于 3"},
+			{Field: "email", Code: "email", Message: "email 不是有效的邮箱地址"},
+			{Field: "age", Code: "max", Message: "age 不能大于 150"},
+			{Field: "status", Code: "oneof", Message: "status 必须是 draft、published 之一"},
+			{Field: "tags", Code: "max", Message: "tags 长度不能超过 2"},
+		}},
+		{"wrong type", `{"user_name":"alice","email":"a@example.com","age":"old"}`, []FieldError{
+			{Field: "age", Code: "type", Message: "age 的类型应为 int"},
+		}},
+		{"malformed json", `{"user_name":`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bind(tt.body)
+			if err == nil {
+				t.Fatal("bind succeeded")
+			}
+			e := FromBinding(err)
+			if e.Kind != KindValidation || e.Code != CodeValidationFailed || e.Status() != http.StatusBadRequest {
+				t.Errorf("error = %+v, want validation_failed", e)
+			}
+			if e.Detail != err.Error() || e.Err == nil || e.Err.Error() != err.Error() {
+				t.Errorf("detail %q, err %v: want the binding error", e.Detail, e.Err)
+			}
+			if !slices.Equal(e.Fields, tt.fields) {
+				t.Errorf("fields = %+v, want %+v", e.Fields, tt.fields)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package apperr
+
+// 错误码，已发布的错误码不要修改含义
+const (
+	CodeInternal = "internal_error"
+
+	// 请求校验
+	CodeValidationFailed = "validation_failed"
+	CodeInvalidQuery     = "invalid_query"
+
+	// 认证和授权
+	CodeUnauthorized        = "unauthorized"
+	CodeInvalidCredentials  = "invalid_credentials"
+	CodeTokenMissing        = "token_missing"
+	CodeTokenInvalid        = "token_invalid"
+	CodeRefreshTokenInvalid = "refresh_token_invalid"
+	CodeRefreshTokenReused  = "refresh_token_reused"
+	CodeForbidden           = "forbidden"
+	CodeEmailNotVerified    = "email_not_verified"
+
+	// 邮箱验证和找回密码
+	CodeVerificationTokenInvalid = "verification_token_invalid"
+	CodeResetTokenInvalid        = "reset_token_invalid"
+	CodeEmailAlreadyVerified     = "email_already_verified"
+	CodeEmailUnchanged           = "email_unchanged"
+
+	// 个人资料
+	CodeCurrentPasswordIncorrect = "current_password_incorrect"
+
+	// 限流
+	CodeRateLimited   = "rate_limited"
+	CodeAccountLocked = "account_locked"
+
+	// 资源不存在
+	CodeRouteNotFound    = "route_not_found"
+	CodeUserNotFound     = "user_not_found"
+	CodePostNotFound     = "post_not_found"
+	CodeCommentNotFound  = "comment_not_found"
+	CodeCategoryNotFound = "category_not_found"
+
+	// 冲突
+	CodeUserExists        = "user_exists"
+	CodeCategorySlugTaken = "category_slug_taken"
+
+	// 业务规则
+	CodeInvalidRole           = "invalid_role"
+	CodeInvalidCategory       = "invalid_category"
+	CodeInvalidParentCategory = "invalid_parent_category"
+	CodeCategoryCycle         = "category_cycle"
+	CodeInvalidParentComment  = "invalid_parent_comment"
+	CodePostNotPublished      = "post_not_published"
+	CodeCommentNotMovable     = "comment_not_movable"
+)
@@ -0,0 +1,422 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/logging"
+	"golang_task4_blog_system/mail"
+	"golang_task4_blog_system/ratelimit"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// 运行环境
+const (
+	EnvDevelopment = "development"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+	EnvTest        = "test"
+)
+
+const minProductionSecretLen = 32
+
+// Config 应用配置
+type Config struct {
+	Env       string          `yaml:"env" toml:"env"`
+	Server    ServerConfig    `yaml:"server" toml:"server"`
+	Database  DatabaseConfig  `yaml:"database" toml:"database"`
+	JWT       JWTConfig       `yaml:"jwt" toml:"jwt"`
+	Scheduler SchedulerConfig `yaml:"scheduler" toml:"scheduler"`
+	Log       LogConfig       `yaml:"log" toml:"log"`
+	RateLimit RateLimitConfig `yaml:"rate_limit" toml:"rate_limit"`
+	Mail      MailConfig      `yaml:"mail" toml:"mail"`
+	Account   AccountConfig   `yaml:"account" toml:"account"`
+}
+
+// ServerConfig HTTP 服务配置，超时为 0 表示不限制
+type ServerConfig struct {
+	Addr              string   `yaml:"addr" toml:"addr"`
+	Mode              string   `yaml:"mode" toml:"mode"` // gin 运行模式：debug、release、test
+	ReadTimeout       Duration `yaml:"read_timeout" toml:"read_timeout"`
+	ReadHeaderTimeout Duration `yaml:"read_header_timeout" toml:"read_header_timeout"`
+	WriteTimeout      Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout       Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	ShutdownTimeout   Duration `yaml:"shutdown_timeout" toml:"shutdown_timeout"` // 退出时等待处理中请求的最长时间
+	// TrustedProxies 可信反向代理的 IP 或 CIDR，只有来自这些地址的请求才按 X-Forwarded-For 取客户端 IP；
+	// 为空时不信任任何代理，客户端 IP 即连接的对端地址
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+}
+
+// DatabaseConfig 数据库配置，字段含义见 database.Config
+type DatabaseConfig struct {
+	Driver          string   `yaml:"driver" toml:"driver"`
+	DSN             string   `yaml:"dsn" toml:"dsn"`
+	Host            string   `yaml:"host" toml:"host"`
+	Port            string   `yaml:"port" toml:"port"`
+	User            string   `yaml:"user" toml:"user"`
+	Password        string   `yaml:"password" toml:"password"`
+	Database        string   `yaml:"database" toml:"database"`
+	SSLMode         string   `yaml:"ssl_mode" toml:"ssl_mode"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+}
+
+// JWTConfig 令牌签名配置
+type JWTConfig struct {
+	Secret string `yaml:"secret" toml:"secret"`
+}
+
+// SchedulerConfig 后台任务配置
+type SchedulerConfig struct {
+	PublishInterval Duration `yaml:"publish_interval" toml:"publish_interval"`
+}
+
+// LogConfig 日志配置
+type LogConfig struct {
+	Level  string `yaml:"level" toml:"level"`   // debug、info、warn、error，debug 时记录全部 SQL
+	Format string `yaml:"format" toml:"format"` // json 或 text
+}
+
+// RateLimitConfig 限流与登录锁定配置
+type RateLimitConfig struct {
+	Login   RateRule      `yaml:"login" toml:"login"`     // 注册、登录和刷新令牌，按 IP
+	Write   RateRule      `yaml:"write" toml:"write"`     // 需要认证的写接口，按用户
+	Comment RateRule      `yaml:"comment" toml:"comment"` // 发表评论，按用户，与 write 同时生效
+	Export  RateRule      `yaml:"export" toml:"export"`   // 导出个人数据，按用户
+	Lockout LockoutConfig `yaml:"lockout" toml:"lockout"`
+}
+
+// RateRule 每 per 时长最多 requests 次请求，burst 为可以连续发出的请求数（默认等于 requests），
+// requests 为 0 表示不限流
+type RateRule struct {
+	Requests int      `yaml:"requests" toml:"requests"`
+	Per      Duration `yaml:"per" toml:"per"`
+	Burst    int      `yaml:"burst" toml:"burst"`
+}
+
+// LockoutConfig 连续登录失败 threshold 次后锁定账户 duration，此后每次失败翻倍，最长 max_duration；
+// 失败计数在 window 内没有新的失败时清零，threshold 为 0 表示不锁定
+type LockoutConfig struct {
+	Threshold   int      `yaml:"threshold" toml:"threshold"`
+	Duration    Duration `yaml:"duration" toml:"duration"`
+	MaxDuration Duration `yaml:"max_duration" toml:"max_duration"`
+	Window      Duration `yaml:"window" toml:"window"`
+}
+
+// MailConfig 邮件发送配置，driver 为 smtp、file（写入 dir 目录）或 log（只写日志）
+type MailConfig struct {
+	Driver string     `yaml:"driver" toml:"driver"`
+	From   string     `yaml:"from" toml:"from"`
+	Dir    string     `yaml:"dir" toml:"dir"`
+	SMTP   SMTPConfig `yaml:"smtp" toml:"smtp"`
+}
+
+// SMTPConfig SMTP 服务器配置，username 为空时不认证
+type SMTPConfig struct {
+	Host     string `yaml:"host" toml:"host"`
+	Port     int    `yaml:"port" toml:"port"`
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// AccountConfig 邮箱验证和密码重置配置，base_url 为邮件中链接指向的前端地址
+type AccountConfig struct {
+	BaseURL          string   `yaml:"base_url" toml:"base_url"`
+	VerificationTTL  Duration `yaml:"verification_ttl" toml:"verification_ttl"`
+	PasswordResetTTL Duration `yaml:"password_reset_ttl" toml:"password_reset_ttl"`
+}
+
+// Limit 转换为令牌桶参数
+func (r RateRule) Limit() ratelimit.Limit {
+	return ratelimit.Every(r.Requests, time.Duration(r.Per), r.Burst)
+}
+
+// Policy 转换为锁定策略
+func (l LockoutConfig) Policy() ratelimit.LockoutPolicy {
+	return ratelimit.LockoutPolicy{
+		Threshold:   l.Threshold,
+		Duration:    time.Duration(l.Duration),
+		MaxDuration: time.Duration(l.MaxDuration),
+		Window:      time.Duration(l.Window),
+	}
+}
+
+// Duration 配置文件中以 "30s"、"5m" 等格式书写的时长
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+// Default 默认配置，配置文件和环境变量在此基础上覆盖
+func Default() *Config {
+	return &Config{
+		Env: EnvDevelopment,
+		Server: ServerConfig{
+			Addr:              ":8080",
+			Mode:              gin.DebugMode,
+			ReadTimeout:       Duration(15 * time.Second),
+			ReadHeaderTimeout: Duration(5 * time.Second),
+			WriteTimeout:      Duration(30 * time.Second),
+			IdleTimeout:       Duration(2 * time.Minute),
+			ShutdownTimeout:   Duration(20 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Driver:          database.DriverSQLite,
+			Database:        "blog.db",
+			MaxIdleConns:    10,
+			MaxOpenConns:    100,
+			ConnMaxLifetime: Duration(time.Hour),
+		},
+		Scheduler: SchedulerConfig{
+			PublishInterval: Duration(time.Minute),
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: logging.FormatJSON,
+		},
+		RateLimit: RateLimitConfig{
+			Login:   RateRule{Requests: 10, Per: Duration(time.Minute)},
+			Write:   RateRule{Requests: 60, Per: Duration(time.Minute), Burst: 20},
+			Comment: RateRule{Requests: 5, Per: Duration(time.Minute)},
+			Export:  RateRule{Requests: 5, Per: Duration(time.Hour)},
+			Lockout: LockoutConfig{
+				Threshold:   5,
+				Duration:    Duration(time.Minute),
+				MaxDuration: Duration(time.Hour),
+				Window:      Duration(24 * time.Hour),
+			},
+		},
+		Mail: MailConfig{
+			Driver: mail.DriverLog,
+			From:   "Blog <no-reply@localhost>",
+			Dir:    "mail",
+			SMTP:   SMTPConfig{Port: 587},
+		},
+		Account: AccountConfig{
+			BaseURL:          "http://localhost:8080",
+			VerificationTTL:  Duration(48 * time.Hour),
+			PasswordResetTTL: Duration(time.Hour),
+		},
+	}
+}
+
+// Load 加载配置，优先级从低到高为：默认值、配置文件、环境配置文件、环境变量。
+//
+// path 为 YAML（.yaml/.yml）或 TOML（.toml）配置文件，为空时只使用默认值和环境变量。
+// 运行环境由 BLOG_ENV 或配置文件中的 env 指定，存在同目录下的 <文件名>.<环境><扩展名>
+// （如 config.production.yaml）时，其中的配置会覆盖主配置文件。
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := decodeFile(path, cfg); err != nil {
+			return nil, err
+		}
+	}
+	if env := os.Getenv("BLOG_ENV"); env != "" {
+		cfg.Env = env
+	}
+	if path != "" {
+		ext := filepath.Ext(path)
+		profile := strings.TrimSuffix(path, ext) + "." + cfg.Env + ext
+		if _, err := os.Stat(profile); err == nil {
+			if err := decodeFile(profile, cfg); err != nil {
+				return nil, err
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func decodeFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(cfg)
+		if errors.Is(err, io.EOF) { // 空文件
+			err = nil
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(cfg)
+	default:
+		return fmt.Errorf("unsupported config format %q in %s", ext, path)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate 检查配置，返回所有问题
+func (cfg *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(cfg.Env != "", "env is required")
+
+	s := cfg.Server
+	check(s.Addr != "", "server.addr is required")
+	check(s.Mode == gin.DebugMode || s.Mode == gin.ReleaseMode || s.Mode == gin.TestMode,
+		"server.mode must be debug, release or test, got %q", s.Mode)
+	check(s.ReadTimeout >= 0 && s.ReadHeaderTimeout >= 0 && s.WriteTimeout >= 0 && s.IdleTimeout >= 0,
+		"server timeouts must not be negative")
+	check(s.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	for _, proxy := range s.TrustedProxies {
+		check(validProxy(proxy), "server.trusted_proxies: %q is not an IP address or CIDR", proxy)
+	}
+
+	db := cfg.Database
+	switch db.Driver {
+	case database.DriverMySQL, database.DriverPostgres:
+		if db.DSN == "" {
+			check(db.Host != "" && db.Port != "" && db.User != "" && db.Database != "",
+				"database.host, port, user and database are required for %s unless dsn is set", db.Driver)
+		}
+	case database.DriverSQLite:
+		check(db.DSN != "" || db.Database != "", "database.database (file path) is required for sqlite unless dsn is set")
+	default:
+		errs = append(errs, fmt.Errorf("database.driver must be mysql, postgres or sqlite, got %q", db.Driver))
+	}
+	check(db.MaxIdleConns >= 0 && db.MaxOpenConns >= 0, "database pool sizes must not be negative")
+	check(db.MaxOpenConns == 0 || db.MaxIdleConns <= db.MaxOpenConns,
+		"database.max_idle_conns (%d) must not exceed max_open_conns (%d)", db.MaxIdleConns, db.MaxOpenConns)
+	check(db.ConnMaxLifetime >= 0, "database.conn_max_lifetime must not be negative")
+
+	check(cfg.JWT.Secret != "", "jwt.secret is required")
+	if cfg.Env == EnvProduction {
+		check(len(cfg.JWT.Secret) >= minProductionSecretLen,
+			"jwt.secret must be at least %d bytes in production", minProductionSecretLen)
+		check(s.Mode == gin.ReleaseMode, "server.mode must be release in production")
+	}
+
+	check(cfg.Scheduler.PublishInterval > 0, "scheduler.publish_interval must be positive")
+
+	var level slog.Level
+	check(level.UnmarshalText([]byte(cfg.Log.Level)) == nil,
+		"log.level must be debug, info, warn or error, got %q", cfg.Log.Level)
+	check(cfg.Log.Format == logging.FormatJSON || cfg.Log.Format == logging.FormatText,
+		"log.format must be json or text, got %q", cfg.Log.Format)
+
+	rules := []struct {
+		name string
+		rule RateRule
+	}{{"login", cfg.RateLimit.Login}, {"write", cfg.RateLimit.Write}, {"comment", cfg.RateLimit.Comment}, {"export", cfg.RateLimit.Export}}
+	for _, r := range rules {
+		check(r.rule.Requests >= 0 && r.rule.Burst >= 0, "rate_limit.%s.requests and burst must not be negative", r.name)
+		check(r.rule.Requests == 0 || r.rule.Per > 0, "rate_limit.%s.per must be positive", r.name)
+	}
+	lo := cfg.RateLimit.Lockout
+	check(lo.Threshold >= 0, "rate_limit.lockout.threshold must not be negative")
+	if lo.Threshold > 0 {
+		check(lo.Duration > 0 && lo.Window > 0, "rate_limit.lockout.duration and window must be positive")
+		check(lo.MaxDuration >= lo.Duration, "rate_limit.lockout.max_duration must not be less than duration")
+	}
+
+	m := cfg.Mail
+	check(m.From != "", "mail.from is required")
+	switch m.Driver {
+	case mail.DriverSMTP:
+		check(m.SMTP.Host != "" && m.SMTP.Port > 0, "mail.smtp.host and port are required for smtp")
+	case mail.DriverFile:
+		check(m.Dir != "", "mail.dir is required for file")
+	case mail.DriverLog:
+	default:
+		errs = append(errs, fmt.Errorf("mail.driver must be smtp, file or log, got %q", m.Driver))
+	}
+	if cfg.Env == EnvProduction {
+		check(m.Driver == mail.DriverSMTP, "mail.driver must be smtp in production")
+	}
+
+	check(cfg.Account.BaseURL != "", "account.base_url is required")
+	check(cfg.Account.VerificationTTL > 0 && cfg.Account.PasswordResetTTL > 0,
+		"account.verification_ttl and password_reset_ttl must be positive")
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// validProxy 是否为 IP 地址或 CIDR，与 gin.Engine.SetTrustedProxies 接受的格式一致
+func validProxy(s string) bool {
+	if strings.Contains(s, "/") {
+		_, _, err := net.ParseCIDR(s)
+		return err == nil
+	}
+	return net.ParseIP(s) != nil
+}
+
+// DBConfig 转换为 database 包的连接配置
+func (cfg *Config) DBConfig() *database.Config {
+	db := cfg.Database
+	return &database.Config{
+		Driver:          db.Driver,
+		DSN:             db.DSN,
+		Host:            db.Host,
+		Port:            db.Port,
+		User:            db.User,
+		Password:        db.Password,
+		Database:        db.Database,
+		SSLMode:         db.SSLMode,
+		MaxIdleConns:    db.MaxIdleConns,
+		MaxOpenConns:    db.MaxOpenConns,
+		ConnMaxLifetime: time.Duration(db.ConnMaxLifetime),
+	}
+}
+
+// MailConfig 转换为 mail 包的配置
+func (cfg *Config) MailConfig() mail.Config {
+	m := cfg.Mail
+	return mail.Config{
+		Driver:   m.Driver,
+		From:     m.From,
+		Dir:      m.Dir,
+		Host:     m.SMTP.Host,
+		Port:     m.SMTP.Port,
+		Username: m.SMTP.Username,
+		Password: m.SMTP.Password,
+	}
+}
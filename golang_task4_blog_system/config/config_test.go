@@ -0,0 +1,207 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/mail"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+// writeFile 在 dir 中写入配置文件，返回文件路径
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestDefault 默认配置使用 SQLite，只需补充 JWT 密钥即可通过校验
+func TestDefault(t *testing.T) {
+	cfg := Default()
+	if cfg.Database.Driver != database.DriverSQLite || cfg.Database.Database != "blog.db" {
+		t.Errorf("default database = %s %s, want sqlite blog.db", cfg.Database.Driver, cfg.Database.Database)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "jwt.secret is required") {
+		t.Errorf("Validate without secret = %v", err)
+	}
+	cfg.JWT.Secret = "dev"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate = %v", err)
+	}
+}
+
+// TestLoadPrecedence 优先级从低到高为默认值、配置文件、环境配置文件、环境变量
+func TestLoadPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := writeFile(t, dir, "config.yaml", `
+server:
+  addr: ":9000"
+  read_timeout: 7s
+jwt:
+  secret: from-file
+log:
+  level: debug
+`)
+	writeFile(t, dir, "config.production.yaml", `
+server:
+  mode: release
+  read_timeout: 9s
+mail:
+  driver: smtp
+  smtp:
+    host: smtp.example.com
+`)
+	t.Setenv("BLOG_ENV", EnvProduction)
+	t.Setenv("BLOG_SERVER_READ_TIMEOUT", "11s")
+	t.Setenv("BLOG_JWT_SECRET", testSecret)
+	t.Setenv("BLOG_SERVER_TRUSTED_PROXIES", " 10.0.0.1, 10.1.0.0/16 ,")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"env", cfg.Env, EnvProduction},
+		{"default kept", cfg.Server.WriteTimeout, Duration(30 * time.Second)},
+		{"file over default", cfg.Server.Addr, ":9000"},
+		{"file kept under profile", cfg.Log.Level, "debug"},
+		{"profile over file", cfg.Server.Mode, gin.ReleaseMode},
+		{"env over profile", cfg.Server.ReadTimeout, Duration(11 * time.Second)},
+		{"env over file", cfg.JWT.Secret, testSecret},
+		{"profile nested field", cfg.Mail.SMTP.Host, "smtp.example.com"},
+		{"nested default kept", cfg.Mail.SMTP.Port, 587},
+		{"env list", strings.Join(cfg.Server.TrustedProxies, "|"), "10.0.0.1|10.1.0.0/16"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+// TestLoadTOML 支持 TOML 配置文件，没有对应环境配置文件时只使用主配置文件
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, t.TempDir(), "config.toml", `
+[jwt]
+secret = "from-toml"
+
+[scheduler]
+publish_interval = "30s"
+`)
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.JWT.Secret != "from-toml" || cfg.Scheduler.PublishInterval != Duration(30*time.Second) {
+		t.Errorf("cfg = %+v %+v", cfg.JWT, cfg.Scheduler)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{"unknown yaml field", "config.yaml", "jwt:\n  secrt: x\n", nil, "secrt"},
+		{"unknown toml field", "config.toml", "[jwt]\nsecrt = \"x\"\n", nil, "missing in the target struct"},
+		{"unsupported format", "config.json", "{}", nil, "unsupported config format"},
+		{"bad duration in file", "config.yaml", "scheduler:\n  publish_interval: soon\n", nil, "invalid duration"},
+		{"bad env int", "config.yaml", "", map[string]string{"BLOG_DATABASE_MAX_OPEN_CONNS": "many"}, "BLOG_DATABASE_MAX_OPEN_CONNS"},
+		{"bad env duration", "config.yaml", "", map[string]string{"BLOG_RATE_LIMIT_LOGIN_PER": "1 minute"}, "BLOG_RATE_LIMIT_LOGIN_PER"},
+		{"invalid after env", "config.yaml", "jwt:\n  secret: x\n", map[string]string{"BLOG_DATABASE_DRIVER": "oracle"}, "database.driver"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, t.TempDir(), tt.file, tt.content)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			_, err := Load(path)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	// production 返回满足生产环境要求的配置
+	production := func() *Config {
+		cfg := Default()
+		cfg.Env = EnvProduction
+		cfg.Server.Mode = gin.ReleaseMode
+		cfg.JWT.Secret = testSecret
+		cfg.Mail.Driver = mail.DriverSMTP
+		cfg.Mail.SMTP.Host = "smtp.example.com"
+		return cfg
+	}
+
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr string // 为空表示校验通过
+	}{
+		{"valid production", func(cfg *Config) {}, ""},
+		{"short secret", func(cfg *Config) { cfg.JWT.Secret = "short" }, "at least 32 bytes"},
+		{"debug mode", func(cfg *Config) { cfg.Server.Mode = gin.DebugMode }, "must be release in production"},
+		{"log mailer", func(cfg *Config) { cfg.Mail.Driver = mail.DriverLog }, "must be smtp in production"},
+		{"smtp without host", func(cfg *Config) { cfg.Mail.SMTP.Host = "" }, "mail.smtp.host and port"},
+		{"short secret outside production", func(cfg *Config) {
+			cfg.Env = EnvStaging
+			cfg.JWT.Secret = "short"
+			cfg.Server.Mode = gin.DebugMode
+			cfg.Mail.Driver = mail.DriverLog
+		}, ""},
+		{"mysql without host", func(cfg *Config) { cfg.Database.Driver = database.DriverMySQL }, "database.host"},
+		{"mysql with dsn", func(cfg *Config) {
+			cfg.Database.Driver = database.DriverMySQL
+			cfg.Database.DSN = "blog:secret@tcp(db)/blog"
+		}, ""},
+		{"idle exceeds open", func(cfg *Config) { cfg.Database.MaxIdleConns = 200 }, "max_idle_conns"},
+		{"bad proxy", func(cfg *Config) { cfg.Server.TrustedProxies = []string{"proxy.local"} }, "trusted_proxies"},
+		{"bad log level", func(cfg *Config) { cfg.Log.Level = "verbose" }, "log.level"},
+		{"rate without period", func(cfg *Config) { cfg.RateLimit.Login.Per = 0 }, "rate_limit.login.per"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := production()
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	// 所有问题一起返回
+	cfg := production()
+	cfg.JWT.Secret = ""
+	cfg.Log.Format = "xml"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "jwt.secret") || !strings.Contains(err.Error(), "log.format") {
+		t.Errorf("Validate = %v, want both jwt.secret and log.format", err)
+	}
+}
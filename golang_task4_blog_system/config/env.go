@@ -0,0 +1,128 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix 环境变量前缀，变量名为 BLOG_<段>_<字段>，如 BLOG_DATABASE_PASSWORD
+const envPrefix = "BLOG_"
+
+// applyEnv 用环境变量覆盖配置，未设置的变量保持原值
+func applyEnv(cfg *Config) error {
+	e := envReader{}
+
+	e.str("SERVER_ADDR", &cfg.Server.Addr)
+	e.str("SERVER_MODE", &cfg.Server.Mode)
+	e.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	e.duration("SERVER_READ_HEADER_TIMEOUT", &cfg.Server.ReadHeaderTimeout)
+	e.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	e.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	e.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	e.list("SERVER_TRUSTED_PROXIES", &cfg.Server.TrustedProxies)
+
+	e.str("DATABASE_DRIVER", &cfg.Database.Driver)
+	e.str("DATABASE_DSN", &cfg.Database.DSN)
+	e.str("DATABASE_HOST", &cfg.Database.Host)
+	e.str("DATABASE_PORT", &cfg.Database.Port)
+	e.str("DATABASE_USER", &cfg.Database.User)
+	e.str("DATABASE_PASSWORD", &cfg.Database.Password)
+	e.str("DATABASE_NAME", &cfg.Database.Database)
+	e.str("DATABASE_SSL_MODE", &cfg.Database.SSLMode)
+	e.int("DATABASE_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	e.int("DATABASE_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	e.duration("DATABASE_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+
+	e.str("JWT_SECRET", &cfg.JWT.Secret)
+
+	e.duration("SCHEDULER_PUBLISH_INTERVAL", &cfg.Scheduler.PublishInterval)
+
+	e.str("LOG_LEVEL", &cfg.Log.Level)
+	e.str("LOG_FORMAT", &cfg.Log.Format)
+
+	e.rule("RATE_LIMIT_LOGIN", &cfg.RateLimit.Login)
+	e.rule("RATE_LIMIT_WRITE", &cfg.RateLimit.Write)
+	e.rule("RATE_LIMIT_COMMENT", &cfg.RateLimit.Comment)
+	e.rule("RATE_LIMIT_EXPORT", &cfg.RateLimit.Export)
+	e.int("RATE_LIMIT_LOCKOUT_THRESHOLD", &cfg.RateLimit.Lockout.Threshold)
+	e.duration("RATE_LIMIT_LOCKOUT_DURATION", &cfg.RateLimit.Lockout.Duration)
+	e.duration("RATE_LIMIT_LOCKOUT_MAX_DURATION", &cfg.RateLimit.Lockout.MaxDuration)
+	e.duration("RATE_LIMIT_LOCKOUT_WINDOW", &cfg.RateLimit.Lockout.Window)
+
+	e.str("MAIL_DRIVER", &cfg.Mail.Driver)
+	e.str("MAIL_FROM", &cfg.Mail.From)
+	e.str("MAIL_DIR", &cfg.Mail.Dir)
+	e.str("MAIL_SMTP_HOST", &cfg.Mail.SMTP.Host)
+	e.int("MAIL_SMTP_PORT", &cfg.Mail.SMTP.Port)
+	e.str("MAIL_SMTP_USERNAME", &cfg.Mail.SMTP.Username)
+	e.str("MAIL_SMTP_PASSWORD", &cfg.Mail.SMTP.Password)
+
+	e.str("ACCOUNT_BASE_URL", &cfg.Account.BaseURL)
+	e.duration("ACCOUNT_VERIFICATION_TTL", &cfg.Account.VerificationTTL)
+	e.duration("ACCOUNT_PASSWORD_RESET_TTL", &cfg.Account.PasswordResetTTL)
+
+	return e.err
+}
+
+// envReader 读取环境变量，记录第一个解析错误
+type envReader struct {
+	err error
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	if e.err != nil {
+		return "", false
+	}
+	return os.LookupEnv(envPrefix + key)
+}
+
+func (e *envReader) str(key string, dst *string) {
+	if v, ok := e.lookup(key); ok {
+		*dst = v
+	}
+}
+
+// list 读取逗号分隔的列表，空字符串表示空列表
+func (e *envReader) list(key string, dst *[]string) {
+	if v, ok := e.lookup(key); ok {
+		var items []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*dst = items
+	}
+}
+
+func (e *envReader) int(key string, dst *int) {
+	if v, ok := e.lookup(key); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			e.err = fmt.Errorf("%s%s: %w", envPrefix, key, err)
+			return
+		}
+		*dst = n
+	}
+}
+
+func (e *envReader) duration(key string, dst *Duration) {
+	if v, ok := e.lookup(key); ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			e.err = fmt.Errorf("%s%s: %w", envPrefix, key, err)
+			return
+		}
+		*dst = Duration(d)
+	}
+}
+
+// rule 读取限流规则的 <prefix>_REQUESTS、<prefix>_PER 和 <prefix>_BURST
+func (e *envReader) rule(prefix string, dst *RateRule) {
+	e.int(prefix+"_REQUESTS", &dst.Requests)
+	e.duration(prefix+"_PER", &dst.Per)
+	e.int(prefix+"_BURST", &dst.Burst)
+}
@@ -0,0 +1,108 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCategories 获取分类树及各分类的已发布文章数
+func (h *PostHandler) GetCategories(c *gin.Context) {
+	categories, err := h.posts.Categories(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"categories": categories,
+	})
+}
+
+// CreateCategory 创建分类（编辑或管理员）
+func (h *PostHandler) CreateCategory(c *gin.Context) {
+	var req models.Category
+
+	// 验证输入数据
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	category, err := h.posts.CreateCategory(c.Request.Context(), service.CategoryInput{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: &req.Description,
+		ParentID:    req.ParentID,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "分类创建成功",
+		"category": category,
+	})
+}
+
+// UpdateCategory 修改分类（编辑或管理员），parent_id 为 0 表示移动到顶级
+func (h *PostHandler) UpdateCategory(c *gin.Context) {
+	var req struct {
+		Name        string  `json:"name" binding:"omitempty,max=100"`
+		Slug        string  `json:"slug" binding:"omitempty,max=120"`
+		Description *string `json:"description" binding:"omitempty,max=500"`
+		ParentID    *uint   `json:"parent_id"`
+	}
+
+	// 验证输入
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	id, ok := idParam(c, "id", errCategoryNotFound())
+	if !ok {
+		return
+	}
+
+	category, err := h.posts.UpdateCategory(c.Request.Context(), id, service.CategoryInput{
+		Name:        req.Name,
+		Slug:        req.Slug,
+		Description: req.Description,
+		ParentID:    req.ParentID,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "分类更新成功",
+		"category": category,
+	})
+}
+
+// DeleteCategory 删除分类（编辑或管理员），其子分类和文章移动到上一级分类
+func (h *PostHandler) DeleteCategory(c *gin.Context) {
+	id, ok := idParam(c, "id", errCategoryNotFound())
+	if !ok {
+		return
+	}
+
+	if err := h.posts.DeleteCategory(c.Request.Context(), id); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "分类删除成功",
+	})
+}
+
+func errCategoryNotFound() *apperr.Error {
+	return apperr.NotFound(apperr.CodeCategoryNotFound, "分类不存在")
+}
@@ -1,143 +1,153 @@
 package controllers
 
 import (
-	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/apperr"
 	"golang_task4_blog_system/middleware"
 	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
+// CommentHandler 评论相关接口
+type CommentHandler struct {
+	comments service.CommentService
+}
+
+// NewCommentHandler 创建评论接口处理器
+func NewCommentHandler(comments service.CommentService) *CommentHandler {
+	return &CommentHandler{comments: comments}
+}
+
 // 创建评论
-func CreateComment(c *gin.Context) {
+func (h *CommentHandler) CreateComment(c *gin.Context) {
 	var req models.Comment
 
 	// 验证输入数据
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "输入验证失败",
-			"message": err.Error(),
-		})
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	// 获取当前用户（通过BasicAuth中间件认证）
-	currentUser := middleware.GetCurrentUser(c)
-	if currentUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "需要认证才能发表评论",
-		})
+	comment, err := h.comments.Create(c.Request.Context(), middleware.GetCurrentUser(c), service.CommentInput{
+		PostID:   req.PostID,
+		ParentID: req.ParentID,
+		Content:  req.Content,
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 检查文章是否存在
-	var post models.Post
-	if err := database.DB.First(&post, req.PostID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "文章不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "检查文章失败",
-		})
-		return
+	message := "评论创建成功"
+	if comment.Status == models.CommentStatusPending {
+		message = "评论已提交，等待审核"
 	}
 
-	// 创建评论
-	comment := models.Comment{
-		Content: req.Content,
-		UserID:  currentUser.ID,
-		PostID:  req.PostID,
+	c.JSON(http.StatusCreated, gin.H{
+		"message": message,
+		"comment": comment,
+	})
+}
+
+// GetPostComments 获取某篇文章已审核通过的评论列表，支持 page/page_size 与 cursor/before 分页，
+// sort 可选 created_at（默认）或 -created_at
+func (h *CommentHandler) GetPostComments(c *gin.Context) {
+	postID, ok := idParam(c, "id", errPostNotFound())
+	if !ok {
+		return
 	}
 
-	// 保存到数据库
-	if err := database.DB.Create(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "创建评论失败",
-		})
+	pq, err := parsePageQuery(c, []string{"created_at"}, "created_at")
+	if err != nil {
+		c.Error(apperr.InvalidQuery(err.Error()))
 		return
 	}
 
-	// 返回创建的评论信息（包含用户信息）
-	var createdComment models.Comment
-	if err := database.DB.Preload("User").First(&createdComment, comment.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取评论详情失败",
-		})
+	comments, total, err := h.comments.ListForPost(c.Request.Context(), middleware.GetCurrentUser(c), postID, false, pq.apply)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "评论创建成功",
-		"comment": createdComment,
+	comments, pagination := paginate(c, pq, comments, total, commentCursor)
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":   comments,
+		"post_id":    postID,
+		"pagination": pagination,
 	})
 }
 
-// GetPostComments 获取某篇文章的所有评论列表
-func GetPostComments(c *gin.Context) {
-	postID := c.Param("postId")
-
-	// 检查文章是否存在
-	var post models.Post
-	if err := database.DB.First(&post, postID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "文章不存在",
-			})
+func commentCursor(cm models.Comment) pageCursor {
+	return pageCursor{CreatedAt: cm.CreatedAt, ID: cm.ID}
+}
+
+const (
+	defaultCommentTreeDepth = 3
+	maxCommentTreeDepth     = 10
+)
+
+// GetPostCommentTree 以树形结构返回文章已审核通过的评论。
+// 顶层评论按 GetPostComments 的方式分页，depth 控制展开的层数（默认 3，最大 10）
+func (h *CommentHandler) GetPostCommentTree(c *gin.Context) {
+	postID, ok := idParam(c, "id", errPostNotFound())
+	if !ok {
+		return
+	}
+
+	depth := defaultCommentTreeDepth
+	if v := c.Query("depth"); v != "" {
+		d, err := strconv.Atoi(v)
+		if err != nil || d < 1 {
+			c.Error(apperr.InvalidQuery("depth 必须是正整数"))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "检查文章失败",
-		})
-		return
+		if d > maxCommentTreeDepth {
+			d = maxCommentTreeDepth
+		}
+		depth = d
 	}
 
-	var comments []models.Comment
-	var total int64
+	pq, err := parsePageQuery(c, []string{"created_at"}, "created_at")
+	if err != nil {
+		c.Error(apperr.InvalidQuery(err.Error()))
+		return
+	}
 
-	// 获取评论总数
-	database.DB.Model(&models.Comment{}).Where("post_id = ?", postID).Count(&total)
+	roots, total, err := h.comments.ListForPost(c.Request.Context(), middleware.GetCurrentUser(c), postID, true, pq.apply)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	roots, pagination := paginate(c, pq, roots, total, commentCursor)
 
-	// 获取评论列表（包含用户信息）
-	if err := database.DB.Preload("User").
-		Where("post_id = ?", postID).
-		Order("created_at ASC"). // 按创建时间正序排列
-		Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取评论列表失败",
-		})
+	tree, err := h.comments.BuildTree(c.Request.Context(), roots, depth)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"comments": comments,
-		"post_id":  postID,
-		"pagination": gin.H{
-			"total": total,
-		},
+		"comments":   tree,
+		"post_id":    postID,
+		"depth":      depth,
+		"pagination": pagination,
 	})
 }
 
 // GetComment 获取单个评论详情
-func GetComment(c *gin.Context) {
-	commentID := c.Param("id")
-
-	var comment models.Comment
-	if err := database.DB.Preload("User").Preload("Post").
-		First(&comment, commentID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "评论不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取评论失败",
-		})
+func (h *CommentHandler) GetComment(c *gin.Context) {
+	id, ok := idParam(c, "id", errCommentNotFound())
+	if !ok {
+		return
+	}
+
+	comment, err := h.comments.Get(c.Request.Context(), middleware.GetCurrentUser(c), id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -146,107 +156,45 @@ func GetComment(c *gin.Context) {
 	})
 }
 
-func UpdateComment(c *gin.Context) {
+// UpdateComment 修改评论内容或移动到其他已发布的文章（post_id 省略时不移动），非审核者修改后需要重新审核
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
 	var req models.Comment
-	commentID := c.Param("id")
 
 	// 验证输入
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "输入验证失败",
-			"message": err.Error(),
-		})
-		return
-	}
-
-	// 查找评论
-	var comment models.Comment
-	if err := database.DB.First(&comment, commentID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "评论不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查找评论失败",
-		})
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	// 检查权限：只有评论作者可以更新
-	currentUser := middleware.GetCurrentUser(c)
-	if comment.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "无权更新此评论",
-		})
+	id, ok := idParam(c, "id", errCommentNotFound())
+	if !ok {
 		return
 	}
 
-	// 检查文章是否存在（如果修改了文章ID）
-	if req.PostID != comment.PostID {
-		var post models.Post
-		if err := database.DB.First(&post, req.PostID).Error; err != nil {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "目标文章不存在",
-			})
-			return
-		}
-	}
-
-	// 更新评论
-	comment.Content = req.Content
-	comment.PostID = req.PostID
-
-	if err := database.DB.Save(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新评论失败",
-		})
+	comment, err := h.comments.Update(c.Request.Context(), middleware.GetCurrentUser(c), id, service.CommentInput{
+		PostID:  req.PostID,
+		Content: req.Content,
+	})
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 重新获取更新后的评论
-	database.DB.Preload("User").First(&comment, commentID)
-
 	c.JSON(http.StatusOK, gin.H{
 		"message": "评论更新成功",
 		"comment": comment,
 	})
 }
 
-
-func DeleteComment(c *gin.Context) {
-	commentID := c.Param("id")
-
-	// 查找评论
-	var comment models.Comment
-	if err := database.DB.First(&comment, commentID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "评论不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查找评论失败",
-		})
+// DeleteComment 删除评论
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	id, ok := idParam(c, "id", errCommentNotFound())
+	if !ok {
 		return
 	}
 
-	// 检查权限：只有评论作者可以删除
-	currentUser := middleware.GetCurrentUser(c)
-	if comment.UserID != currentUser.ID {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "无权删除此评论",
-		})
-		return
-	}
-
-	// 删除评论
-	if err := database.DB.Delete(&comment).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除评论失败",
-		})
+	if err := h.comments.Delete(c.Request.Context(), middleware.GetCurrentUser(c), id); err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -256,29 +204,16 @@ func DeleteComment(c *gin.Context) {
 }
 
 // GetMyComments 获取当前用户的所有评论
-func GetMyComments(c *gin.Context) {
+func (h *CommentHandler) GetMyComments(c *gin.Context) {
 	currentUser := middleware.GetCurrentUser(c)
 	if currentUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "需要认证",
-		})
+		c.Error(apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证"))
 		return
 	}
 
-	var comments []models.Comment
-	var total int64
-
-	// 获取评论总数
-	database.DB.Model(&models.Comment{}).Where("user_id = ?", currentUser.ID).Count(&total)
-
-	// 获取评论列表（包含用户和文章信息）
-	if err := database.DB.Preload("User").Preload("Post").
-		Where("user_id = ?", currentUser.ID).
-		Order("created_at DESC").
-		Find(&comments).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取评论失败",
-		})
+	comments, total, err := h.comments.ListByUser(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
@@ -289,3 +224,7 @@ func GetMyComments(c *gin.Context) {
 		},
 	})
 }
+
+func errCommentNotFound() *apperr.Error {
+	return apperr.NotFound(apperr.CodeCommentNotFound, "评论不存在")
+}
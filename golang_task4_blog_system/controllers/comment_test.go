@@ -0,0 +1,188 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// commentFixture 基于内存仓储的评论接口。
+// 文章 1（已发布）和 3（草稿）属于 alice，文章 2（已发布）属于 bob；评论均由 rita 发表：
+// 评论 1、6 为文章 1 的顶层评论，3 回复 1，4 回复 3，5 回复 1 但待审核；评论 2 为文章 2 的顶层评论
+type commentFixture struct {
+	comments *memComments
+	tokens   map[string]string // 用户名到访问令牌
+	router   *gin.Engine
+}
+
+func newCommentFixture(t *testing.T) *commentFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	middleware.SetJWTSecret(testJWTSecret)
+
+	users := &memUsers{}
+	f := &commentFixture{tokens: make(map[string]string)}
+	for _, u := range []struct{ name, role string }{
+		{"alice", models.RoleAuthor},
+		{"bob", models.RoleAuthor},
+		{"rita", models.RoleReader},
+		{"adam", models.RoleAdmin},
+	} {
+		f.tokens[u.name] = accessToken(t, addUser(t, users, u.name, u.role))
+	}
+
+	parent := func(id uint) *uint { return &id }
+	posts := &memPosts{posts: map[uint]*models.Post{
+		1: {ID: 1, UserID: 1, Status: models.PostStatusPublished},
+		2: {ID: 2, UserID: 2, Status: models.PostStatusPublished},
+		3: {ID: 3, UserID: 1, Status: models.PostStatusDraft},
+	}}
+	f.comments = &memComments{posts: posts, comments: map[uint]*models.Comment{
+		1: {ID: 1, PostID: 1, UserID: 3, Status: models.CommentStatusApproved},
+		2: {ID: 2, PostID: 2, UserID: 3, Status: models.CommentStatusApproved},
+		3: {ID: 3, PostID: 1, UserID: 3, Status: models.CommentStatusApproved, ParentID: parent(1)},
+		4: {ID: 4, PostID: 1, UserID: 3, Status: models.CommentStatusApproved, ParentID: parent(3)},
+		5: {ID: 5, PostID: 1, UserID: 3, Status: models.CommentStatusPending, ParentID: parent(1)},
+		6: {ID: 6, PostID: 1, UserID: 3, Status: models.CommentStatusApproved},
+	}}
+	h := NewCommentHandler(service.NewCommentService(f.comments, posts))
+
+	f.router = gin.New()
+	f.router.Use(middleware.Errors())
+	f.router.GET("/api/posts/:id/comments/tree", h.GetPostCommentTree)
+	auth := f.router.Group("/api", middleware.Auth(service.NewUserService(users, &memRefreshTokens{}, nil)))
+	auth.POST("/comments", middleware.RequirePermission(models.PermCommentsCreate), h.CreateComment)
+	auth.PUT("/comments/:id", middleware.RequirePermission(models.PermCommentsUpdateOwn, models.PermCommentsUpdateAny), h.UpdateComment)
+	return f
+}
+
+// treeNode 按ID查找评论树中某一层的节点
+func treeNode(t *testing.T, nodes any, id uint) map[string]any {
+	t.Helper()
+	items, _ := nodes.([]any)
+	for _, item := range items {
+		node, _ := item.(map[string]any)
+		if node["id"] == float64(id) {
+			return node
+		}
+	}
+	t.Fatalf("comment %d not found in %v", id, nodes)
+	return nil
+}
+
+func TestCommentTreeDepth(t *testing.T) {
+	f := newCommentFixture(t)
+
+	// node 节点展开的直接回复ID和 reply_count
+	type node struct {
+		replies []uint
+		count   float64
+	}
+	tests := []struct {
+		depth int
+		want  map[uint]node
+	}{
+		{1, map[uint]node{1: {nil, 1}, 6: {nil, 0}}},
+		{2, map[uint]node{1: {[]uint{3}, 1}, 3: {nil, 1}}},
+		{3, map[uint]node{1: {[]uint{3}, 1}, 3: {[]uint{4}, 1}, 4: {nil, 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("depth %d", tt.depth), func(t *testing.T) {
+			code, resp := doJSON(t, f.router, "GET", fmt.Sprintf("/api/posts/1/comments/tree?depth=%d", tt.depth), "", nil)
+			if code != http.StatusOK {
+				t.Fatalf("tree: %d %v", code, resp)
+			}
+			if ids := commentIDs(resp["comments"]); len(ids) != 2 || ids[0] != 1 || ids[1] != 6 {
+				t.Fatalf("roots = %v, want [1 6]", ids)
+			}
+
+			// 按层查找节点：1 和 6 在第一层，3 在 1 的回复中，4 在 3 的回复中
+			root := treeNode(t, resp["comments"], 1)
+			nodes := map[uint]map[string]any{1: root, 6: treeNode(t, resp["comments"], 6)}
+			if tt.depth >= 2 {
+				nodes[3] = treeNode(t, root["replies"], 3)
+			}
+			if tt.depth >= 3 {
+				nodes[4] = treeNode(t, nodes[3]["replies"], 4)
+			}
+
+			for id, want := range tt.want {
+				node := nodes[id]
+				// 待审核的回复 5 不计入 reply_count，也不出现在回复中
+				if node["reply_count"] != want.count {
+					t.Errorf("comment %d reply_count = %v, want %v", id, node["reply_count"], want.count)
+				}
+				if got := commentIDs(node["replies"]); fmt.Sprint(got) != fmt.Sprint(want.replies) {
+					t.Errorf("comment %d replies = %v, want %v", id, got, want.replies)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateReply(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       gin.H
+		wantStatus int
+		wantCode   string
+	}{
+		{"reply on same post", gin.H{"post_id": 1, "parent_id": 1, "content": "re"}, http.StatusCreated, ""},
+		{"parent on another post", gin.H{"post_id": 1, "parent_id": 2, "content": "re"}, http.StatusBadRequest, apperr.CodeInvalidParentComment},
+		{"parent pending", gin.H{"post_id": 1, "parent_id": 5, "content": "re"}, http.StatusNotFound, apperr.CodeCommentNotFound},
+		{"draft post", gin.H{"post_id": 3, "content": "hi"}, http.StatusNotFound, apperr.CodePostNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCommentFixture(t)
+			code, resp := doJSON(t, f.router, "POST", "/api/comments", f.tokens["rita"], tt.body)
+			if code != tt.wantStatus {
+				t.Fatalf("create: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" && resp["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestUpdateCommentMove(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		id         uint
+		body       gin.H
+		wantStatus int
+		wantCode   string
+		wantPost   uint // 评论更新后所属的文章
+	}{
+		{"post_id omitted keeps post", "rita", 6, gin.H{"content": "edited"}, http.StatusOK, "", 1},
+		{"move to published post", "rita", 6, gin.H{"content": "edited", "post_id": 2}, http.StatusOK, "", 2},
+		{"move to missing post", "rita", 6, gin.H{"content": "edited", "post_id": 99}, http.StatusNotFound, apperr.CodePostNotFound, 1},
+		{"move to invisible draft", "rita", 6, gin.H{"content": "edited", "post_id": 3}, http.StatusNotFound, apperr.CodePostNotFound, 1},
+		{"move to visible draft", "adam", 6, gin.H{"content": "edited", "post_id": 3}, http.StatusBadRequest, apperr.CodePostNotPublished, 1},
+		{"move reply", "rita", 3, gin.H{"content": "edited", "post_id": 2}, http.StatusBadRequest, apperr.CodeCommentNotMovable, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newCommentFixture(t)
+			code, resp := doJSON(t, f.router, "PUT", fmt.Sprintf("/api/comments/%d", tt.id), f.tokens[tt.user], tt.body)
+			if code != tt.wantStatus {
+				t.Fatalf("update: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" && resp["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+			}
+			if got := f.comments.comments[tt.id].PostID; got != tt.wantPost {
+				t.Errorf("comment %d post_id = %d, want %d", tt.id, got, tt.wantPost)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"fmt"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/service"
+	"log/slog"
+	"mime"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler 个人数据导出接口
+type ExportHandler struct {
+	exports service.ExportService
+}
+
+// NewExportHandler 创建数据导出接口处理器
+func NewExportHandler(exports service.ExportService) *ExportHandler {
+	return &ExportHandler{exports: exports}
+}
+
+// ExportMe 以附件形式下载当前用户的资料、文章和评论，format 为 zip（默认）或 json。
+// 导出内容边读边写，响应开始后无法再返回错误，中途出错时客户端收到的 ZIP 或 JSON 不完整、无法解析
+func (h *ExportHandler) ExportMe(c *gin.Context) {
+	format := c.DefaultQuery("format", service.ExportFormatZIP)
+	var contentType string
+	switch format {
+	case service.ExportFormatZIP:
+		contentType = "application/zip"
+	case service.ExportFormatJSON:
+		contentType = "application/json; charset=utf-8"
+	default:
+		c.Error(apperr.InvalidQuery("format 必须是 zip 或 json"))
+		return
+	}
+
+	user := middleware.GetCurrentUser(c)
+	filename := fmt.Sprintf("blog-export-%s-%s.%s", user.Username, time.Now().Format("20060102"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+	c.Header("Cache-Control", "no-store")
+	c.Status(200)
+
+	if err := h.exports.Export(c.Request.Context(), user, format, c.Writer); err != nil {
+		slog.ErrorContext(c.Request.Context(), "data export failed", "user_id", user.ID, "error", err)
+		c.Error(err)
+	}
+}
@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/mail"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+// doJSON 发送 JSON 请求并解析响应
+func doJSON(t *testing.T, router http.Handler, method, path, token string, body any) (int, map[string]any) {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var resp map[string]any
+	if w.Body.Len() > 0 {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid JSON response %q", method, path, w.Body.String())
+		}
+	}
+	return w.Code, resp
+}
+
+// outbox 记录发出的邮件，代替真实的发送方式
+type outbox struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mail.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.messages = append(o.messages, msg)
+	return nil
+}
+
+func (o *outbox) last(t *testing.T) mail.Message {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.messages) == 0 {
+		t.Fatal("no mail sent")
+	}
+	return o.messages[len(o.messages)-1]
+}
+
+// 以下为处理函数测试使用的内存仓储。只实现测试用到的方法，
+// 其余方法由嵌入的接口提供，被调用时因接口为 nil 而 panic
+
+// 编译期检查内存仓储实现了对应接口
+var (
+	_ repository.UserRepository         = (*memUsers)(nil)
+	_ repository.RefreshTokenRepository = (*memRefreshTokens)(nil)
+	_ repository.CommentRepository      = (*memComments)(nil)
+	_ repository.PostRepository         = (*memPosts)(nil)
+)
+
+// memUsers 内存用户仓储
+type memUsers struct {
+	repository.UserRepository
+	mu     sync.Mutex
+	users  []*models.User
+	nextID uint
+}
+
+func (r *memUsers) add(user *models.User) *models.User {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	user.ID = r.nextID
+	r.users = append(r.users, user)
+	return user
+}
+
+func (r *memUsers) find(match func(u *models.User) bool) (*models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, u := range r.users {
+		if match(u) {
+			copied := *u
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memUsers) Create(ctx context.Context, user *models.User) error {
+	if conflicts, _ := r.FindConflicts(ctx, user.Username, user.Email); len(conflicts) > 0 {
+		return repository.ErrDuplicated
+	}
+	r.add(user)
+	return nil
+}
+
+func (r *memUsers) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id })
+}
+
+func (r *memUsers) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username })
+}
+
+func (r *memUsers) FindConflicts(ctx context.Context, username, email string) ([]models.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var conflicts []models.User
+	for _, u := range r.users {
+		if u.Username == username || u.Email == email {
+			conflicts = append(conflicts, *u)
+		}
+	}
+	return conflicts, nil
+}
+
+// memUserTokens 内存一次性令牌仓储，注册时发送验证邮件用到
+type memUserTokens struct {
+	repository.UserTokenRepository
+	mu     sync.Mutex
+	tokens []models.UserToken
+}
+
+func (r *memUserTokens) Create(ctx context.Context, token *models.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uint(len(r.tokens) + 1)
+	r.tokens = append(r.tokens, *token)
+	return nil
+}
+
+// memRefreshTokens 内存刷新令牌仓储
+type memRefreshTokens struct {
+	mu     sync.Mutex
+	tokens []*models.RefreshToken
+}
+
+func (r *memRefreshTokens) Create(ctx context.Context, token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token.ID = uint(len(r.tokens) + 1)
+	copied := *token
+	r.tokens = append(r.tokens, &copied)
+	return nil
+}
+
+func (r *memRefreshTokens) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.TokenHash == hash {
+			copied := *t
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrNotFound
+}
+
+func (r *memRefreshTokens) Rotate(ctx context.Context, current, next *models.RefreshToken, at time.Time) error {
+	if err := r.Create(ctx, next); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	stored := r.tokens[current.ID-1]
+	if stored.RevokedAt != nil || stored.ReplacedByID != nil {
+		return repository.ErrNotFound
+	}
+	nextID := next.ID
+	stored.RevokedAt, stored.ReplacedByID = &at, &nextID
+	return nil
+}
+
+func (r *memRefreshTokens) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, t := range r.tokens {
+		if t.FamilyID == familyID && t.RevokedAt == nil {
+			t.RevokedAt = &at
+		}
+	}
+	return nil
+}
+
+// revoked 统计已吊销的令牌数
+func (r *memRefreshTokens) revoked() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := 0
+	for _, t := range r.tokens {
+		if t.RevokedAt != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// memPosts 内存文章仓储
+type memPosts struct {
+	repository.PostRepository
+	posts map[uint]*models.Post
+}
+
+func (r *memPosts) FindByID(ctx context.Context, id uint) (*models.Post, error) {
+	post, ok := r.posts[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *post
+	return &copied, nil
+}
+
+// memComments 内存评论仓储，列表查询忽略分页条件
+type memComments struct {
+	repository.CommentRepository
+	mu       sync.Mutex
+	posts    *memPosts
+	comments map[uint]*models.Comment
+}
+
+func (r *memComments) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment, ok := r.comments[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *comment
+	return &copied, nil
+}
+
+func (r *memComments) FindWithUser(ctx context.Context, id uint) (*models.Comment, error) {
+	return r.FindByID(ctx, id)
+}
+
+func (r *memComments) FindByIDs(ctx context.Context, ids []uint) ([]models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var comments []models.Comment
+	for _, id := range ids {
+		if comment, ok := r.comments[id]; ok {
+			comments = append(comments, *comment)
+		}
+	}
+	return comments, nil
+}
+
+func (r *memComments) UpdateStatus(ctx context.Context, ids []uint, status string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var updated int64
+	for _, id := range ids {
+		if comment, ok := r.comments[id]; ok {
+			comment.Status = status
+			updated++
+		}
+	}
+	return updated, nil
+}
+
+func (r *memComments) ListForModeration(ctx context.Context, filter repository.ModerationFilter, page repository.Scope) ([]models.Comment, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comments := []models.Comment{}
+	for _, id := range slices.Sorted(maps.Keys(r.comments)) {
+		comment := r.comments[id]
+		if (filter.Status != "" && comment.Status != filter.Status) ||
+			(filter.PostID != 0 && comment.PostID != filter.PostID) ||
+			(filter.PostAuthorID != 0 && r.posts.posts[comment.PostID].UserID != filter.PostAuthorID) {
+			continue
+		}
+		comments = append(comments, *comment)
+	}
+	return comments, int64(len(comments)), nil
+}
+
+func (r *memComments) Create(ctx context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comment.ID = uint(len(r.comments) + 1)
+	for r.comments[comment.ID] != nil {
+		comment.ID++
+	}
+	comment.CreatedAt = time.Now()
+	copied := *comment
+	r.comments[comment.ID] = &copied
+	return nil
+}
+
+func (r *memComments) Save(ctx context.Context, comment *models.Comment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *comment
+	r.comments[comment.ID] = &copied
+	return nil
+}
+
+func (r *memComments) ListApproved(ctx context.Context, postID uint, rootsOnly bool, page repository.Scope) ([]models.Comment, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	comments := []models.Comment{}
+	for _, id := range slices.Sorted(maps.Keys(r.comments)) {
+		comment := r.comments[id]
+		if comment.PostID == postID && comment.Status == models.CommentStatusApproved &&
+			(!rootsOnly || comment.ParentID == nil) {
+			comments = append(comments, *comment)
+		}
+	}
+	return comments, int64(len(comments)), nil
+}
+
+func (r *memComments) ApprovedReplies(ctx context.Context, parentIDs []uint) ([]models.Comment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var replies []models.Comment
+	for _, id := range slices.Sorted(maps.Keys(r.comments)) {
+		comment := r.comments[id]
+		if comment.ParentID != nil && slices.Contains(parentIDs, *comment.ParentID) &&
+			comment.Status == models.CommentStatusApproved {
+			replies = append(replies, *comment)
+		}
+	}
+	return replies, nil
+}
+
+func (r *memComments) CountApprovedReplies(ctx context.Context, parentIDs []uint) (map[uint]int64, error) {
+	replies, err := r.ApprovedReplies(ctx, parentIDs)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int64)
+	for _, reply := range replies {
+		counts[*reply.ParentID]++
+	}
+	return counts, nil
+}
+
+func (r *memComments) CountReplies(ctx context.Context, id uint) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, comment := range r.comments {
+		if comment.ParentID != nil && *comment.ParentID == id {
+			count++
+		}
+	}
+	return count, nil
+}
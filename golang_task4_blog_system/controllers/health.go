@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// readyPingTimeout 就绪检查中数据库 ping 的超时时间，应短于编排系统的探测超时
+const readyPingTimeout = 2 * time.Second
+
+// dbPoolStats 数据库连接池状态，对应 sql.DBStats
+type dbPoolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+// Healthz 存活检查：进程能处理请求即返回 200，不检查依赖
+func Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status": "ok",
+	})
+}
+
+// HealthHandler 依赖数据库的就绪检查接口
+type HealthHandler struct {
+	db *gorm.DB
+}
+
+// NewHealthHandler 创建就绪检查接口处理器
+func NewHealthHandler(db *gorm.DB) *HealthHandler {
+	return &HealthHandler{db: db}
+}
+
+// Readyz 就绪检查：ping 数据库并返回连接池状态，数据库不可用时返回 503
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"error":   "数据库不可用",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readyPingTimeout)
+	defer cancel()
+	pingErr := sqlDB.PingContext(ctx)
+
+	s := sqlDB.Stats()
+	stats := dbPoolStats{
+		MaxOpenConnections: s.MaxOpenConnections,
+		OpenConnections:    s.OpenConnections,
+		InUse:              s.InUse,
+		Idle:               s.Idle,
+		WaitCount:          s.WaitCount,
+		WaitDuration:       s.WaitDuration.String(),
+		MaxIdleClosed:      s.MaxIdleClosed,
+		MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  s.MaxLifetimeClosed,
+	}
+
+	if pingErr != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status":   "unavailable",
+			"error":    "数据库不可用",
+			"message":  pingErr.Error(),
+			"database": stats,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":   "ok",
+		"database": stats,
+	})
+}
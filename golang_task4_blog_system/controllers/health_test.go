@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestReadyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newSQLiteDB(t)
+	router := gin.New()
+	router.GET("/healthz", Healthz)
+	router.GET("/readyz", NewHealthHandler(db).Readyz)
+
+	statsFields := []string{
+		"max_open_connections", "open_connections", "in_use", "idle", "wait_count",
+		"wait_duration", "max_idle_closed", "max_idle_time_closed", "max_lifetime_closed",
+	}
+	checkStats := func(t *testing.T, resp map[string]any) {
+		t.Helper()
+		stats, _ := resp["database"].(map[string]any)
+		for _, field := range statsFields {
+			if _, ok := stats[field]; !ok {
+				t.Errorf("database stats missing %s: %v", field, stats)
+			}
+		}
+	}
+
+	code, resp := doJSON(t, router, "GET", "/readyz", "", nil)
+	if code != http.StatusOK || resp["status"] != "ok" {
+		t.Fatalf("readyz: %d %v", code, resp)
+	}
+	checkStats(t, resp)
+
+	// 关闭连接池后 ping 失败，就绪检查返回 503，存活检查不受影响
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.Close()
+
+	code, resp = doJSON(t, router, "GET", "/readyz", "", nil)
+	if code != http.StatusServiceUnavailable || resp["status"] != "unavailable" || resp["message"] == "" {
+		t.Fatalf("readyz after close: %d %v", code, resp)
+	}
+	checkStats(t, resp)
+
+	if code, resp := doJSON(t, router, "GET", "/healthz", "", nil); code != http.StatusOK || resp["status"] != "ok" {
+		t.Errorf("healthz after close: %d %v", code, resp)
+	}
+}
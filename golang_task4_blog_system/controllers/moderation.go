@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ApproveComment 审核通过评论（文章作者、编辑或管理员）
+func (h *CommentHandler) ApproveComment(c *gin.Context) {
+	h.setCommentStatus(c, models.CommentStatusApproved)
+}
+
+// RejectComment 驳回评论（文章作者、编辑或管理员）
+func (h *CommentHandler) RejectComment(c *gin.Context) {
+	h.setCommentStatus(c, models.CommentStatusRejected)
+}
+
+func (h *CommentHandler) setCommentStatus(c *gin.Context, status string) {
+	id, ok := idParam(c, "id", errCommentNotFound())
+	if !ok {
+		return
+	}
+
+	comment, err := h.comments.Moderate(c.Request.Context(), middleware.GetCurrentUser(c), id, status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "评论审核成功",
+		"comment": comment,
+	})
+}
+
+// ModerateComments 批量审核评论，请求中的评论必须全部有权审核，否则整体拒绝
+func (h *CommentHandler) ModerateComments(c *gin.Context) {
+	var req struct {
+		CommentIDs []uint `json:"comment_ids" binding:"required,min=1,max=100"`
+		Status     string `json:"status" binding:"required,oneof=approved rejected"`
+	}
+
+	// 验证输入
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	updated, err := h.comments.ModerateBatch(c.Request.Context(), middleware.GetCurrentUser(c), req.CommentIDs, req.Status)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "批量审核成功",
+		"status":  req.Status,
+		"updated": updated,
+	})
+}
+
+// GetModerationQueue 获取待审核的评论：拥有 comments:moderate:any 权限可见全部，其他用户只看到自己文章下的评论。
+// status 默认 pending，可选 approved、rejected；post_id 只看某篇文章；分页参数同 GetPostComments
+func (h *CommentHandler) GetModerationQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.CommentStatusPending)
+	switch status {
+	case models.CommentStatusPending, models.CommentStatusApproved, models.CommentStatusRejected:
+	default:
+		c.Error(apperr.InvalidQuery("status 必须是 pending、approved 或 rejected"))
+		return
+	}
+
+	var postID uint
+	if v := c.Query("post_id"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperr.InvalidQuery("post_id 必须是正整数"))
+			return
+		}
+		postID = uint(id)
+	}
+
+	pq, err := parsePageQuery(c, []string{"created_at"}, "created_at")
+	if err != nil {
+		c.Error(apperr.InvalidQuery(err.Error()))
+		return
+	}
+
+	comments, total, err := h.comments.ModerationQueue(c.Request.Context(), middleware.GetCurrentUser(c), status, postID, pq.apply)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	comments, pagination := paginate(c, pq, comments, total, commentCursor)
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":   comments,
+		"status":     status,
+		"pagination": pagination,
+	})
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// moderationFixture 基于内存仓储的评论审核接口。
+// 文章 1 属于 alice，文章 2 属于 bob；评论 1（文章 1）和评论 2（文章 2）待审核，评论 3（文章 1）已通过
+type moderationFixture struct {
+	comments *memComments
+	tokens   map[string]string // 用户名到访问令牌
+	router   *gin.Engine
+}
+
+func newModerationFixture(t *testing.T) *moderationFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	middleware.SetJWTSecret(testJWTSecret)
+
+	users := &memUsers{}
+	f := &moderationFixture{tokens: make(map[string]string)}
+	for _, u := range []struct{ name, role string }{
+		{"alice", models.RoleAuthor},
+		{"bob", models.RoleAuthor},
+		{"erin", models.RoleEditor},
+		{"rita", models.RoleReader},
+	} {
+		f.tokens[u.name] = accessToken(t, addUser(t, users, u.name, u.role))
+	}
+
+	posts := &memPosts{posts: map[uint]*models.Post{
+		1: {ID: 1, UserID: 1, Status: models.PostStatusPublished},
+		2: {ID: 2, UserID: 2, Status: models.PostStatusPublished},
+	}}
+	f.comments = &memComments{posts: posts, comments: map[uint]*models.Comment{
+		1: {ID: 1, PostID: 1, UserID: 4, Status: models.CommentStatusPending},
+		2: {ID: 2, PostID: 2, UserID: 4, Status: models.CommentStatusPending},
+		3: {ID: 3, PostID: 1, UserID: 4, Status: models.CommentStatusApproved},
+	}}
+	h := NewCommentHandler(service.NewCommentService(f.comments, posts))
+
+	f.router = gin.New()
+	f.router.Use(middleware.Errors())
+	auth := f.router.Group("/api", middleware.Auth(service.NewUserService(users, &memRefreshTokens{}, nil)))
+	moderate := middleware.RequirePermission(models.PermCommentsModerateOwn, models.PermCommentsModerateAny)
+	auth.PUT("/comments/:id/approve", moderate, h.ApproveComment)
+	auth.PUT("/comments/:id/reject", moderate, h.RejectComment)
+	auth.POST("/comments/moderate", moderate, h.ModerateComments)
+	auth.GET("/moderation/comments", moderate, h.GetModerationQueue)
+	return f
+}
+
+func (f *moderationFixture) status(id uint) string {
+	return f.comments.comments[id].Status
+}
+
+// commentIDs 取出响应中的评论ID列表
+func commentIDs(v any) []uint {
+	var ids []uint
+	items, _ := v.([]any)
+	for _, item := range items {
+		switch item := item.(type) {
+		case float64:
+			ids = append(ids, uint(item))
+		case map[string]any:
+			id, _ := item["id"].(float64)
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+func TestSetCommentStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		path       string
+		wantStatus int
+		wantCode   string
+		wantState  string // 评论 1 的审核状态
+	}{
+		{"post author approves", "alice", "/api/comments/1/approve", http.StatusOK, "", models.CommentStatusApproved},
+		{"post author rejects", "alice", "/api/comments/1/reject", http.StatusOK, "", models.CommentStatusRejected},
+		{"editor approves any", "erin", "/api/comments/1/approve", http.StatusOK, "", models.CommentStatusApproved},
+		{"other author forbidden", "bob", "/api/comments/1/approve", http.StatusForbidden, apperr.CodeForbidden, models.CommentStatusPending},
+		{"reader lacks permission", "rita", "/api/comments/1/approve", http.StatusForbidden, apperr.CodeForbidden, models.CommentStatusPending},
+		{"missing comment", "erin", "/api/comments/99/approve", http.StatusNotFound, apperr.CodeCommentNotFound, models.CommentStatusPending},
+		{"invalid id", "erin", "/api/comments/abc/approve", http.StatusNotFound, apperr.CodeCommentNotFound, models.CommentStatusPending},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModerationFixture(t)
+
+			code, resp := doJSON(t, f.router, "PUT", tt.path, f.tokens[tt.user], nil)
+			if code != tt.wantStatus {
+				t.Fatalf("PUT %s: %d %v, want %d", tt.path, code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" && resp["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+			}
+			if got := f.status(1); got != tt.wantState {
+				t.Errorf("comment 1 status = %s, want %s", got, tt.wantState)
+			}
+		})
+	}
+}
+
+func TestModerateComments(t *testing.T) {
+	tests := []struct {
+		name        string
+		user        string
+		body        gin.H
+		wantStatus  int
+		wantCode    string
+		wantIDs     []uint // 错误响应中的 comment_ids
+		wantUpdated float64
+	}{
+		{"own posts", "alice", gin.H{"comment_ids": []uint{1, 3}, "status": "rejected"}, http.StatusOK, "", nil, 2},
+		{"editor any post", "erin", gin.H{"comment_ids": []uint{1, 2}, "status": "approved"}, http.StatusOK, "", nil, 2},
+		{"missing comments", "erin", gin.H{"comment_ids": []uint{1, 98, 99}, "status": "approved"}, http.StatusNotFound, apperr.CodeCommentNotFound, []uint{98, 99}, 0},
+		{"partly forbidden", "alice", gin.H{"comment_ids": []uint{1, 2}, "status": "approved"}, http.StatusForbidden, apperr.CodeForbidden, []uint{2}, 0},
+		{"invalid status", "erin", gin.H{"comment_ids": []uint{1}, "status": "pending"}, http.StatusBadRequest, apperr.CodeValidationFailed, nil, 0},
+		{"empty ids", "erin", gin.H{"comment_ids": []uint{}, "status": "approved"}, http.StatusBadRequest, apperr.CodeValidationFailed, nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModerationFixture(t)
+
+			code, resp := doJSON(t, f.router, "POST", "/api/comments/moderate", f.tokens[tt.user], tt.body)
+			if code != tt.wantStatus {
+				t.Fatalf("moderate: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" {
+				if resp["code"] != tt.wantCode {
+					t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+				}
+				if got := commentIDs(resp["comment_ids"]); fmt.Sprint(got) != fmt.Sprint(tt.wantIDs) {
+					t.Errorf("comment_ids = %v, want %v", got, tt.wantIDs)
+				}
+				// 整体拒绝时任何评论都不应被修改
+				if f.status(1) != models.CommentStatusPending || f.status(2) != models.CommentStatusPending {
+					t.Errorf("rejected request changed statuses: %s, %s", f.status(1), f.status(2))
+				}
+				return
+			}
+			if resp["updated"] != tt.wantUpdated {
+				t.Errorf("updated = %v, want %v", resp["updated"], tt.wantUpdated)
+			}
+		})
+	}
+}
+
+func TestGetModerationQueue(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       string
+		query      string
+		wantStatus int
+		wantIDs    []uint
+	}{
+		{"author sees own posts", "alice", "", http.StatusOK, []uint{1}},
+		{"editor sees all", "erin", "", http.StatusOK, []uint{1, 2}},
+		{"editor filters by post", "erin", "?post_id=2", http.StatusOK, []uint{2}},
+		{"author filters other post", "alice", "?post_id=2", http.StatusOK, nil},
+		{"approved", "alice", "?status=approved", http.StatusOK, []uint{3}},
+		{"invalid status", "erin", "?status=deleted", http.StatusBadRequest, nil},
+		{"invalid post id", "erin", "?post_id=abc", http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newModerationFixture(t)
+
+			code, resp := doJSON(t, f.router, "GET", "/api/moderation/comments"+tt.query, f.tokens[tt.user], nil)
+			if code != tt.wantStatus {
+				t.Fatalf("queue: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if code != http.StatusOK {
+				if resp["code"] != apperr.CodeInvalidQuery {
+					t.Errorf("code = %v, want %s", resp["code"], apperr.CodeInvalidQuery)
+				}
+				return
+			}
+			if got := commentIDs(resp["comments"]); fmt.Sprint(got) != fmt.Sprint(tt.wantIDs) {
+				t.Errorf("comments = %v, want %v", got, tt.wantIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// pageSort 列表排序方式
+type pageSort struct {
+	Column string
+	Desc   bool
+}
+
+// pageCursor 游标分页位置：created_at + id
+type pageCursor struct {
+	CreatedAt time.Time
+	ID        uint
+}
+
+// pageQuery 从查询参数解析出的分页条件。
+// 支持 page/page_size 偏移分页，以及 cursor（向后翻页）/before（向前翻页）游标分页，
+// 游标分页仅在按 created_at 排序时可用。
+type pageQuery struct {
+	Page     int
+	PageSize int
+	After    *pageCursor
+	Before   *pageCursor
+	Sort     pageSort
+}
+
+func (pc pageCursor) encode() string {
+	raw := fmt.Sprintf("%d:%d", pc.CreatedAt.UnixNano(), pc.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (*pageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("无效的游标")
+	}
+	nanos, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, errors.New("无效的游标")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, errors.New("无效的游标")
+	}
+	i, err := strconv.ParseUint(id, 10, 64)
+	if err != nil {
+		return nil, errors.New("无效的游标")
+	}
+	return &pageCursor{CreatedAt: time.Unix(0, n), ID: uint(i)}, nil
+}
+
+// parsePageQuery 解析分页与排序参数，allowedSorts 为允许排序的列，defaultSort 如 "-created_at"
+func parsePageQuery(c *gin.Context, allowedSorts []string, defaultSort string) (*pageQuery, error) {
+	q := &pageQuery{Page: 1, PageSize: defaultPageSize}
+
+	if v := c.Query("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return nil, errors.New("page 必须是正整数")
+		}
+		q.Page = page
+	}
+
+	if v := c.Query("page_size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < 1 {
+			return nil, errors.New("page_size 必须是正整数")
+		}
+		if size > maxPageSize {
+			size = maxPageSize
+		}
+		q.PageSize = size
+	}
+
+	sortParam := c.DefaultQuery("sort", defaultSort)
+	column := strings.TrimPrefix(sortParam, "-")
+	allowed := false
+	for _, s := range allowedSorts {
+		if s == column {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return nil, fmt.Errorf("不支持的排序字段: %s", column)
+	}
+	q.Sort = pageSort{Column: column, Desc: strings.HasPrefix(sortParam, "-")}
+
+	if v := c.Query("cursor"); v != "" {
+		cur, err := decodeCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		q.After = cur
+	}
+	if v := c.Query("before"); v != "" {
+		cur, err := decodeCursor(v)
+		if err != nil {
+			return nil, err
+		}
+		q.Before = cur
+	}
+	if q.After != nil && q.Before != nil {
+		return nil, errors.New("cursor 与 before 不能同时使用")
+	}
+	if q.cursorMode() && q.Sort.Column != "created_at" {
+		return nil, errors.New("游标分页仅支持按 created_at 排序")
+	}
+
+	return q, nil
+}
+
+func (q *pageQuery) cursorMode() bool {
+	return q.After != nil || q.Before != nil
+}
+
+// apply 为查询追加排序、游标条件和 limit，多取一条用于判断是否还有下一页
+func (q *pageQuery) apply(db *gorm.DB) *gorm.DB {
+	desc := q.Sort.Desc
+	cur := q.After
+	if q.Before != nil {
+		// 向前翻页时反向查询，取回后再翻转
+		desc = !desc
+		cur = q.Before
+	}
+
+	dir := "ASC"
+	op := ">"
+	if desc {
+		dir = "DESC"
+		op = "<"
+	}
+
+	if cur != nil {
+		db = db.Where(
+			fmt.Sprintf("(created_at %s ? OR (created_at = ? AND id %s ?))", op, op),
+			cur.CreatedAt, cur.CreatedAt, cur.ID,
+		)
+	} else {
+		db = db.Offset((q.Page - 1) * q.PageSize)
+	}
+
+	return db.Order(fmt.Sprintf("%s %s", q.Sort.Column, dir)).
+		Order("id " + dir).
+		Limit(q.PageSize + 1)
+}
+
+// paginate 截掉多取的一条记录，并生成分页信息和上一页/下一页链接
+func paginate[T any](c *gin.Context, q *pageQuery, items []T, total int64, cursorOf func(T) pageCursor) ([]T, gin.H) {
+	hasMore := len(items) > q.PageSize
+	if hasMore {
+		items = items[:q.PageSize]
+	}
+	if q.Before != nil {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	var hasNext, hasPrev bool
+	switch {
+	case q.After != nil:
+		hasNext, hasPrev = hasMore, true
+	case q.Before != nil:
+		hasNext, hasPrev = true, hasMore
+	default:
+		hasNext, hasPrev = hasMore, q.Page > 1
+	}
+
+	pagination := gin.H{
+		"total":     total,
+		"page_size": q.PageSize,
+		"sort":      q.sortParam(),
+		"next":      nil,
+		"prev":      nil,
+	}
+
+	if !q.cursorMode() {
+		totalPages := (total + int64(q.PageSize) - 1) / int64(q.PageSize)
+		pagination["page"] = q.Page
+		pagination["total_pages"] = totalPages
+		if hasNext {
+			pagination["next"] = pageLink(c, map[string]string{"page": strconv.Itoa(q.Page + 1)})
+		}
+		if hasPrev {
+			pagination["prev"] = pageLink(c, map[string]string{"page": strconv.Itoa(q.Page - 1)})
+		}
+	}
+
+	// 按 created_at 排序时提供游标，客户端可随时切换到游标分页
+	if q.Sort.Column == "created_at" && len(items) > 0 {
+		if hasNext {
+			next := cursorOf(items[len(items)-1]).encode()
+			pagination["next_cursor"] = next
+			if q.cursorMode() {
+				pagination["next"] = pageLink(c, map[string]string{"cursor": next})
+			}
+		}
+		if hasPrev && q.cursorMode() {
+			prev := cursorOf(items[0]).encode()
+			pagination["prev_cursor"] = prev
+			pagination["prev"] = pageLink(c, map[string]string{"before": prev})
+		}
+	}
+
+	return items, pagination
+}
+
+func (q *pageQuery) sortParam() string {
+	if q.Sort.Desc {
+		return "-" + q.Sort.Column
+	}
+	return q.Sort.Column
+}
+
+// pageLink 基于当前请求地址替换分页参数生成链接
+func pageLink(c *gin.Context, params map[string]string) string {
+	values := url.Values{}
+	for k, v := range c.Request.URL.Query() {
+		values[k] = v
+	}
+	for _, k := range []string{"page", "cursor", "before"} {
+		values.Del(k)
+	}
+	for k, v := range params {
+		values.Set(k, v)
+	}
+
+	u := url.URL{Path: c.Request.URL.Path, RawQuery: values.Encode()}
+	return u.String()
+}
+
+// parseTimeParam 解析日期参数，支持 RFC3339 和 2006-01-02；
+// endOfDay 为 true 时仅日期的值取次日零点，用于区间上限
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的日期: %s", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor pageCursor
+	}{
+		{"nanoseconds", pageCursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456789, time.UTC), ID: 42}},
+		{"zero id", pageCursor{CreatedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ID: 0}},
+		{"large id", pageCursor{CreatedAt: time.Unix(0, 1), ID: 1<<32 - 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := tt.cursor.encode()
+			if strings.ContainsAny(encoded, "+/=") {
+				t.Fatalf("cursor %q is not URL safe", encoded)
+			}
+			got, err := decodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("decodeCursor(%q): %v", encoded, err)
+			}
+			if !got.CreatedAt.Equal(tt.cursor.CreatedAt) || got.ID != tt.cursor.ID {
+				t.Fatalf("decodeCursor(%q) = %+v, want %+v", encoded, *got, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestDecodeCursorRejectsMalformed(t *testing.T) {
+	raw := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not base64", "!!!"},
+		{"padded base64", base64.URLEncoding.EncodeToString([]byte("1:23"))},
+		{"missing separator", raw("1700000000")},
+		{"empty parts", raw(":")},
+		{"non numeric time", raw("abc:1")},
+		{"non numeric id", raw("1700000000:x")},
+		{"negative id", raw("1700000000:-1")},
+		{"trailing garbage", raw("1700000000:1:2")},
+		{"time overflow", raw("99999999999999999999:1")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := decodeCursor(tt.input); err == nil {
+				t.Fatalf("decodeCursor(%q) = %+v, want error", tt.input, *c)
+			}
+		})
+	}
+}
+
+func newQueryContext(query string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("GET", "/api/posts?"+query, nil)
+	return c
+}
+
+func TestParsePageQueryCursors(t *testing.T) {
+	cur := pageCursor{CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: 7}.encode()
+	sorts := []string{"created_at", "title"}
+	tests := []struct {
+		name       string
+		query      string
+		wantErr    bool
+		wantAfter  bool
+		wantBefore bool
+	}{
+		{"after", "cursor=" + cur, false, true, false},
+		{"before", "before=" + cur, false, false, true},
+		{"both directions", "cursor=" + cur + "&before=" + cur, true, false, false},
+		{"tampered cursor", "cursor=" + cur[:len(cur)-2] + "!!", true, false, false},
+		{"cursor with title sort", "sort=title&cursor=" + cur, true, false, false},
+		{"offset", "page=2", false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q, err := parsePageQuery(newQueryContext(tt.query), sorts, "-created_at")
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parsePageQuery(%q) succeeded, want error", tt.query)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parsePageQuery(%q): %v", tt.query, err)
+			}
+			if (q.After != nil) != tt.wantAfter || (q.Before != nil) != tt.wantBefore {
+				t.Fatalf("parsePageQuery(%q) after=%v before=%v", tt.query, q.After, q.Before)
+			}
+		})
+	}
+}
+
+func TestPageQueryApplyDirection(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cur := &pageCursor{CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: 7}
+	desc := pageSort{Column: "created_at", Desc: true}
+
+	tests := []struct {
+		name  string
+		query pageQuery
+		want  []string
+	}{
+		{"after descending", pageQuery{PageSize: 10, After: cur, Sort: desc},
+			[]string{"created_at < ?", "id < ?", "ORDER BY created_at DESC,id DESC", "LIMIT 11"}},
+		{"before descending reverses", pageQuery{PageSize: 10, Before: cur, Sort: desc},
+			[]string{"created_at > ?", "id > ?", "ORDER BY created_at ASC,id ASC", "LIMIT 11"}},
+		{"offset", pageQuery{Page: 3, PageSize: 5, Sort: desc},
+			[]string{"ORDER BY created_at DESC,id DESC", "LIMIT 6 OFFSET 10"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var rows []struct{ ID uint }
+			sql := db.Table("posts").Scopes(tt.query.apply).Find(&rows).Statement.SQL.String()
+			for _, want := range tt.want {
+				if !strings.Contains(sql, want) {
+					t.Errorf("SQL %q does not contain %q", sql, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPaginateBefore(t *testing.T) {
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	// 向前翻页按反向顺序取回 page_size+1 条：ID 5、4、3 中多取的 3 表示前面还有数据
+	items := []pageCursor{
+		{CreatedAt: base.Add(5 * time.Minute), ID: 5},
+		{CreatedAt: base.Add(4 * time.Minute), ID: 4},
+		{CreatedAt: base.Add(3 * time.Minute), ID: 3},
+	}
+	q := &pageQuery{PageSize: 2, Before: &items[0], Sort: pageSort{Column: "created_at"}}
+
+	got, pagination := paginate(newQueryContext("before=x"), q, items, 10, func(p pageCursor) pageCursor { return p })
+	if len(got) != 2 || got[0].ID != 4 || got[1].ID != 5 {
+		t.Fatalf("items = %+v, want IDs 4, 5", got)
+	}
+	if pagination["next_cursor"] != got[1].encode() {
+		t.Errorf("next_cursor = %v, want cursor of last item", pagination["next_cursor"])
+	}
+	if pagination["prev_cursor"] != got[0].encode() {
+		t.Errorf("prev_cursor = %v, want cursor of first item", pagination["prev_cursor"])
+	}
+	if next, _ := pagination["next"].(string); !strings.Contains(next, "cursor=") || strings.Contains(next, "before=") {
+		t.Errorf("next link = %q", next)
+	}
+}
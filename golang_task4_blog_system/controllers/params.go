@@ -0,0 +1,18 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// idParam 解析路径参数中的ID，不是正整数时按资源不存在处理
+func idParam(c *gin.Context, name string, notFound *apperr.Error) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil || id == 0 {
+		c.Error(notFound)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -1,248 +1,261 @@
 package controllers
 
 import (
-	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/apperr"
 	"golang_task4_blog_system/middleware"
 	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"golang_task4_blog_system/service"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
-	"gorm.io/gorm"
 )
 
-// 创建文章
-func CreatePost(c *gin.Context) {
-	var req models.Post
+// PostHandler 文章相关接口
+type PostHandler struct {
+	posts service.PostService
+}
 
-	// 验证输入数据
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "输入验证失败",
-			"message": err.Error(),
-		})
-		return
-	}
+// NewPostHandler 创建文章接口处理器
+func NewPostHandler(posts service.PostService) *PostHandler {
+	return &PostHandler{posts: posts}
+}
 
-	// 获取当前用户（通过BasicAuth中间件认证）
-	currentUser := middleware.GetCurrentUser(c)
-	if currentUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "需要认证才能创建文章",
-		})
-		return
+// postRequest 创建文章的请求，tags 为标签名列表，不存在的标签自动创建
+type postRequest struct {
+	models.Post
+	Tags []string `json:"tags"`
+}
+
+func (r *postRequest) input() service.PostInput {
+	return service.PostInput{
+		Title:      r.Title,
+		Content:    r.Content,
+		Slug:       r.Slug,
+		Status:     r.Status,
+		PublishAt:  r.PublishAt,
+		CategoryID: r.CategoryID,
+		Tags:       r.Tags,
 	}
+}
 
-	// 创建文章
-	post := models.Post{
-		Title:   req.Title,
-		Content: req.Content,
-		UserID:  currentUser.ID,
+// updatePostRequest 更新文章的请求，所有字段均可省略，省略的字段保持不变
+type updatePostRequest struct {
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Slug       string     `json:"slug" binding:"omitempty,max=255"`
+	Status     string     `json:"status" binding:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt  *time.Time `json:"publish_at"`
+	CategoryID *uint      `json:"category_id"`
+	Tags       []string   `json:"tags"`
+}
+
+func (r *updatePostRequest) input() service.PostInput {
+	return service.PostInput{
+		Title:      r.Title,
+		Content:    r.Content,
+		Slug:       r.Slug,
+		Status:     r.Status,
+		PublishAt:  r.PublishAt,
+		CategoryID: r.CategoryID,
+		Tags:       r.Tags,
 	}
+}
+
+// 创建文章
+func (h *PostHandler) CreatePost(c *gin.Context) {
+	var req postRequest
 
-	// 保存到数据库
-	if err := database.DB.Create(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "创建文章失败",
-		})
+	// 验证输入数据
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	// 返回创建的文章信息（包含用户信息）
-	var createdPost models.Post
-	if err := database.DB.Preload("User").First(&createdPost, post.ID).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取文章详情失败",
-		})
+	post, err := h.posts.Create(c.Request.Context(), middleware.GetCurrentUser(c), req.input())
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, gin.H{
 		"message": "文章创建成功",
-		"post":    createdPost,
+		"post":    post,
 	})
 }
 
-// 获取所有文章
-func GetPosts(c *gin.Context) {
-	var posts []models.Post
-	var total int64
+// 获取文章列表，支持分页、排序和筛选：
+//
+//	page/page_size      偏移分页
+//	cursor/before       基于 created_at+id 的游标分页
+//	sort                created_at、updated_at、title，前缀 "-" 表示倒序，默认 -created_at
+//	author/author_id    按作者用户名或ID筛选
+//	from/to             按创建时间区间筛选（to 为上限，仅日期时包含当天）
+//	title               按标题关键字筛选
+//	tag                 按标签名或 slug 筛选，可重复指定，需同时包含所有标签
+//	category            按分类ID或 slug 筛选，包含子分类
+//
+// 列表只包含已发布的文章
+func (h *PostHandler) GetPosts(c *gin.Context) {
+	filter := repository.PostFilter{Status: models.PostStatusPublished}
+
+	filter.Author = c.Query("author")
+	if authorID := c.Query("author_id"); authorID != "" {
+		id, err := strconv.ParseUint(authorID, 10, 32)
+		if err != nil || id == 0 {
+			c.Error(apperr.InvalidQuery("author_id 必须是正整数"))
+			return
+		}
+		filter.UserID = uint(id)
+	}
+	if from := c.Query("from"); from != "" {
+		t, err := parseTimeParam(from, false)
+		if err != nil {
+			c.Error(apperr.InvalidQuery(err.Error()))
+			return
+		}
+		filter.From = &t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseTimeParam(to, true)
+		if err != nil {
+			c.Error(apperr.InvalidQuery(err.Error()))
+			return
+		}
+		filter.To = &t
+	}
+	filter.Title = strings.TrimSpace(c.Query("title"))
 
-	// 获取文章总数
-	database.DB.Model(&models.Post{}).Count(&total)
+	h.listPosts(c, filter)
+}
 
-	// 获取文章列表（包含用户信息）
-	if err := database.DB.Preload("User").
-		Order("created_at DESC").
-		Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取文章列表失败",
-		})
+// GetMyPosts 获取当前用户的文章（包含所有状态），分页、排序以及标签和分类筛选同 GetPosts，
+// 可通过 status 按状态筛选
+func (h *PostHandler) GetMyPosts(c *gin.Context) {
+	currentUser := middleware.GetCurrentUser(c)
+	if currentUser == nil {
+		c.Error(apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
-		"pagination": gin.H{
-			"total": total,
-		},
+	h.listPosts(c, repository.PostFilter{
+		UserID: currentUser.ID,
+		Status: c.Query("status"),
 	})
 }
 
-// 获取单个文章
-func GetPost(c *gin.Context) {
-	id := c.Param("id")
+// listPosts 补充标签和分类筛选条件，按分页参数返回文章列表
+func (h *PostHandler) listPosts(c *gin.Context, filter repository.PostFilter) {
+	pq, err := parsePageQuery(c, []string{"created_at", "updated_at", "title"}, "-created_at")
+	if err != nil {
+		c.Error(apperr.InvalidQuery(err.Error()))
+		return
+	}
 
-	var post models.Post
-	// 获取文章详情，包含用户信息和评论
-	if err := database.DB.Preload("User").Preload("Comments.User").
-		First(&post, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "文章不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取文章失败",
-		})
+	filter.Tags = c.QueryArray("tag")
+	filter.Category = c.Query("category")
+
+	posts, total, err := h.posts.List(c.Request.Context(), filter, pq.apply)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
+	posts, pagination := paginate(c, pq, posts, total, postCursor)
+
 	c.JSON(http.StatusOK, gin.H{
-		"post": post,
+		"posts":      posts,
+		"pagination": pagination,
 	})
 }
 
-// 更新文章
-func UpdatePost(c *gin.Context) {
-	var req models.Post
-	PostID := c.Param("id")
+func postCursor(p models.Post) pageCursor {
+	return pageCursor{CreatedAt: p.CreatedAt, ID: p.ID}
+}
 
-	// 验证输入
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "输入验证失败",
-			"message": err.Error(),
-		})
+// 获取单个文章，包含用户信息和已审核通过的评论
+func (h *PostHandler) GetPost(c *gin.Context) {
+	id, ok := idParam(c, "id", errPostNotFound())
+	if !ok {
 		return
 	}
 
-	// 查找文章
-	var post models.Post
-	if err := database.DB.First(&post, PostID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "文章不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查找文章失败",
-		})
+	post, err := h.posts.Get(c.Request.Context(), middleware.GetCurrentUser(c), id)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 检查权限：只有文章作者可以更新
-	postIDUint, _ := strconv.ParseUint(PostID, 10, 32)
-	if !middleware.IsPostAuthor(c, uint(postIDUint)) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "无权更新此文章",
-		})
-		return
-	}
+	c.JSON(http.StatusOK, gin.H{
+		"post": post,
+	})
+}
 
-	// 构建更新数据
-	updates := make(map[string]interface{})
-	if req.Title != "" {
-		updates["title"] = req.Title
-	}
-	if req.Content != "" {
-		updates["content"] = req.Content
+// GetPostBySlug 通过 slug 获取文章，旧 slug 以 301 重定向到当前 slug
+func (h *PostHandler) GetPostBySlug(c *gin.Context) {
+	post, moved, err := h.posts.GetBySlug(c.Request.Context(), middleware.GetCurrentUser(c), c.Param("slug"))
+	if err != nil {
+		c.Error(err)
+		return
 	}
-
-	// 更新文章
-	if err := database.DB.Model(&post).Updates(updates).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "更新文章失败",
-		})
+	if moved {
+		c.Redirect(http.StatusMovedPermanently, "/api/posts/by-slug/"+url.PathEscape(post.Slug))
 		return
 	}
 
-	// 重新获取更新后的文章
-	database.DB.Preload("User").First(&post, PostID)
-
 	c.JSON(http.StatusOK, gin.H{
-		"message": "文章更新成功",
-		"post":    post,
+		"post": post,
 	})
 }
 
-// 删除文章
-func DeletePost(c *gin.Context) {
-	postID := c.Param("id")
+// 更新文章，tags 未指定时保持不变，为空数组时清空；category_id 为 0 表示取消分类
+func (h *PostHandler) UpdatePost(c *gin.Context) {
+	var req updatePostRequest
 
-	// 查找文章
-	var post models.Post
-	if err := database.DB.First(&post, postID).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "文章不存在",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "查找文章失败",
-		})
+	// 验证输入
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	// 检查权限：只有文章作者可以删除
-	postIDUint, _ := strconv.ParseUint(postID, 10, 32)
-	if !middleware.IsPostAuthor(c, uint(postIDUint)) {
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "无权删除此文章",
-		})
+	id, ok := idParam(c, "id", errPostNotFound())
+	if !ok {
 		return
 	}
 
-	// 删除文章（GORM 会自动处理关联的评论，因为有外键约束）
-	if err := database.DB.Delete(&post).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "删除文章失败",
-		})
+	post, err := h.posts.Update(c.Request.Context(), middleware.GetCurrentUser(c), id, req.input())
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "文章删除成功",
+		"message": "文章更新成功",
+		"post":    post,
 	})
 }
 
-// GetMyPosts 获取当前用户的文章
-func GetMyPosts(c *gin.Context) {
-	currentUser := middleware.GetCurrentUser(c)
-	if currentUser == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"error": "需要认证",
-		})
+// 删除文章，关联的评论由外键约束级联删除
+func (h *PostHandler) DeletePost(c *gin.Context) {
+	id, ok := idParam(c, "id", errPostNotFound())
+	if !ok {
 		return
 	}
 
-	var posts []models.Post
-	if err := database.DB.Preload("User").
-		Where("user_id = ?", currentUser.ID).
-		Order("created_at DESC").
-		Find(&posts).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": "获取文章失败",
-		})
+	if err := h.posts.Delete(c.Request.Context(), middleware.GetCurrentUser(c), id); err != nil {
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"posts": posts,
+		"message": "文章删除成功",
 	})
 }
 
-
+func errPostNotFound() *apperr.Error {
+	return apperr.NotFound(apperr.CodePostNotFound, "文章不存在")
+}
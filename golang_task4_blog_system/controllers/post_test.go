@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestUpdatePostPartial 更新文章时只修改请求中指定的字段，创建文章仍要求标题和内容
+func TestUpdatePostPartial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	middleware.SetJWTSecret(testJWTSecret)
+	db := newSQLiteDB(t)
+
+	now := time.Now()
+	author := &models.User{Username: "alice", Email: "alice@example.com", Password: "x", Role: models.RoleAuthor, EmailVerifiedAt: &now}
+	if err := db.Create(author).Error; err != nil {
+		t.Fatal(err)
+	}
+	token := accessToken(t, author)
+
+	users := service.NewUserService(repository.NewUserRepository(db), repository.NewRefreshTokenRepository(db), nil)
+	h := NewPostHandler(service.NewPostService(repository.NewPostRepository(db), repository.NewCommentRepository(db)))
+	router := gin.New()
+	router.Use(middleware.Errors())
+	auth := router.Group("/api", middleware.Auth(users))
+	auth.POST("/posts", h.CreatePost)
+	auth.PUT("/posts/:id", h.UpdatePost)
+
+	if code, resp := doJSON(t, router, "POST", "/api/posts", token, gin.H{"title": "Hello"}); code != http.StatusBadRequest {
+		t.Fatalf("create without content: %d %v", code, resp)
+	}
+	code, resp := doJSON(t, router, "POST", "/api/posts", token, gin.H{"title": "Hello", "content": "Body", "tags": []string{"go"}})
+	if code != http.StatusCreated {
+		t.Fatalf("create: %d %v", code, resp)
+	}
+	created, _ := resp["post"].(map[string]any)
+	path := fmt.Sprintf("/api/posts/%v", created["id"])
+
+	tests := []struct {
+		name  string
+		body  gin.H
+		code  int
+		check func(t *testing.T, post map[string]any)
+	}{
+		{"title only", gin.H{"title": "Renamed"}, http.StatusOK, func(t *testing.T, post map[string]any) {
+			if post["title"] != "Renamed" || post["content"] != "Body" || post["slug"] != "renamed" {
+				t.Errorf("post = %v", post)
+			}
+			if tags, _ := post["tags"].([]any); len(tags) != 1 {
+				t.Errorf("tags = %v, want unchanged", post["tags"])
+			}
+		}},
+		{"status only", gin.H{"status": models.PostStatusDraft}, http.StatusOK, func(t *testing.T, post map[string]any) {
+			if post["status"] != models.PostStatusDraft || post["title"] != "Renamed" || post["content"] != "Body" {
+				t.Errorf("post = %v", post)
+			}
+		}},
+		{"empty body", gin.H{}, http.StatusOK, func(t *testing.T, post map[string]any) {
+			if post["title"] != "Renamed" || post["status"] != models.PostStatusDraft {
+				t.Errorf("post = %v", post)
+			}
+		}},
+		{"invalid status", gin.H{"status": "deleted"}, http.StatusBadRequest, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, resp := doJSON(t, router, "PUT", path, token, tt.body)
+			if code != tt.code {
+				t.Fatalf("update: %d %v, want %d", code, resp, tt.code)
+			}
+			if tt.check != nil {
+				post, _ := resp["post"].(map[string]any)
+				tt.check(t, post)
+			}
+		})
+	}
+}
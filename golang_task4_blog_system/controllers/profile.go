@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetMe 获取当前用户的账户信息和个人资料
+func (h *UserHandler) GetMe(c *gin.Context) {
+	c.JSON(200, gin.H{"user": middleware.GetCurrentUser(c)})
+}
+
+// UpdateMe 修改当前用户的个人资料，未指定的字段保持不变，空字符串表示清空
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	var input struct {
+		DisplayName *string `json:"display_name" binding:"omitempty,max=50"`
+		Bio         *string `json:"bio" binding:"omitempty,max=500"`
+		AvatarURL   *string `json:"avatar_url" binding:"omitempty,max=255"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	user, err := h.users.UpdateProfile(c.Request.Context(), middleware.GetCurrentUser(c), service.ProfileInput{
+		DisplayName: input.DisplayName,
+		Bio:         input.Bio,
+		AvatarURL:   input.AvatarURL,
+	})
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "资料修改成功", "user": user})
+}
+
+// ChangePassword 校验当前密码后设置新密码。其他设备上的登录随之失效，
+// 当前客户端使用响应中新签发的令牌继续访问
+func (h *UserHandler) ChangePassword(c *gin.Context) {
+	var input struct {
+		CurrentPassword string `json:"current_password" binding:"required"`
+		NewPassword     string `json:"new_password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	user := middleware.GetCurrentUser(c)
+	if err := h.users.ChangePassword(c.Request.Context(), user, input.CurrentPassword, input.NewPassword); err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp, err := issueTokenPair(c.Request.Context(), h.users, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp["message"] = "密码修改成功"
+	c.JSON(200, resp)
+}
+
+// ChangeEmail 校验密码后向新邮箱发送验证邮件，验证通过后邮箱才会修改
+func (h *UserHandler) ChangeEmail(c *gin.Context) {
+	var input struct {
+		Email    string `json:"email" binding:"required,email,max=100"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	user := middleware.GetCurrentUser(c)
+	if err := h.users.VerifyPassword(c.Request.Context(), user, input.Password); err != nil {
+		c.Error(err)
+		return
+	}
+	if err := h.accounts.ChangeEmail(c.Request.Context(), user, input.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "验证邮件已发送到新邮箱，验证后生效"})
+}
+
+// GetUserProfile 按用户名获取用户的公开资料
+func (h *UserHandler) GetUserProfile(c *gin.Context) {
+	user, err := h.users.GetByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"user": user.Profile()})
+}
+
+// DeleteMe 校验密码后注销当前用户。文章和评论保留并显示为已注销用户，注销后无法恢复
+func (h *UserHandler) DeleteMe(c *gin.Context) {
+	var input struct {
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	if err := h.users.DeleteAccount(c.Request.Context(), middleware.GetCurrentUser(c), input.Password); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "账户已注销"})
+}
@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/search"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Search 全文搜索已发布的文章和已审核通过的评论，按相关度排序：
+//
+//	q             搜索关键字（必填）
+//	type          all（默认）、posts 或 comments
+//	page/page_size 分页
+func (h *PostHandler) Search(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.Error(apperr.InvalidQuery("q 不能为空"))
+		return
+	}
+
+	var kinds []string
+	switch c.DefaultQuery("type", "all") {
+	case "posts":
+		kinds = []string{search.KindPost}
+	case "comments":
+		kinds = []string{search.KindComment}
+	case "all":
+	default:
+		c.Error(apperr.InvalidQuery("type 必须是 all、posts 或 comments"))
+		return
+	}
+
+	// 搜索结果只按相关度排序，不支持游标分页
+	pq, err := parsePageQuery(c, []string{"relevance"}, "relevance")
+	if err != nil {
+		c.Error(apperr.InvalidQuery(err.Error()))
+		return
+	}
+
+	results, err := h.posts.Search(c.Request.Context(), q, kinds...)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	total := int64(len(results))
+	offset := (pq.Page - 1) * pq.PageSize
+	if offset > len(results) {
+		offset = len(results)
+	}
+	end := min(offset+pq.PageSize+1, len(results))
+
+	page, pagination := paginate(c, pq, results[offset:end], total, nil)
+
+	c.JSON(http.StatusOK, gin.H{
+		"query":      q,
+		"results":    page,
+		"pagination": pagination,
+	})
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/migrations"
+	"golang_task4_blog_system/repository"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// newSQLiteDB 打开执行过全部迁移的 SQLite 内存数据库，测试结束时关闭
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestSQLiteRoundTrip 在执行过迁移的 SQLite 内存数据库上走完注册、验证邮箱、登录和获取个人信息
+func TestSQLiteRoundTrip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := newSQLiteDB(t)
+	middleware.SetJWTSecret("sqlite-round-trip-test-secret-0123456789")
+
+	sent := &outbox{}
+	userRepo := repository.NewUserRepository(db)
+	accounts := service.NewAccountService(userRepo, repository.NewUserTokenRepository(db), sent, service.AccountOptions{
+		BaseURL:          "http://blog.test",
+		VerificationTTL:  time.Hour,
+		PasswordResetTTL: time.Hour,
+	})
+	userService := service.NewUserService(userRepo, repository.NewRefreshTokenRepository(db), nil)
+	users := NewUserHandler(userService, accounts)
+
+	router := gin.New()
+	router.Use(middleware.Errors())
+	router.POST("/api/register", users.Register)
+	router.POST("/api/login", users.Login)
+	router.POST("/api/email/verify", users.VerifyEmail)
+	router.GET("/api/me", middleware.Auth(userService), users.GetMe)
+
+	code, resp := doJSON(t, router, "POST", "/api/register", "", gin.H{"username": "alice", "email": "alice@example.com", "password": "secret123"})
+	if code != http.StatusOK {
+		t.Fatalf("register: %d %v", code, resp)
+	}
+
+	token := regexp.MustCompile(`verify-email\?token=([A-Za-z0-9_-]+)`).FindStringSubmatch(sent.last(t).Body)
+	if token == nil {
+		t.Fatalf("verification link not found in %q", sent.last(t).Body)
+	}
+	if code, resp := doJSON(t, router, "POST", "/api/email/verify", "", gin.H{"token": token[1]}); code != http.StatusOK {
+		t.Fatalf("verify email: %d %v", code, resp)
+	}
+
+	code, resp = doJSON(t, router, "POST", "/api/login", "", gin.H{"username": "alice", "password": "secret123"})
+	if code != http.StatusOK {
+		t.Fatalf("login: %d %v", code, resp)
+	}
+	access, _ := resp["access_token"].(string)
+	if access == "" {
+		t.Fatalf("login response has no access_token: %v", resp)
+	}
+
+	code, resp = doJSON(t, router, "GET", "/api/me", access, nil)
+	if code != http.StatusOK {
+		t.Fatalf("me: %d %v", code, resp)
+	}
+	user, _ := resp["user"].(map[string]any)
+	if user["username"] != "alice" || user["email"] != "alice@example.com" || user["email_verified_at"] == nil {
+		t.Errorf("me = %v", user)
+	}
+
+	if code, _ := doJSON(t, router, "GET", "/api/me", "", nil); code != http.StatusUnauthorized {
+		t.Errorf("me without token: %d, want 401", code)
+	}
+}
@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"golang_task4_blog_system/apperr"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultTagLimit = 50
+	maxTagLimit     = 200
+)
+
+// GetTags 标签云，只统计已发布的文章，没有已发布文章的标签不返回：
+//
+//	sort   count（默认，按文章数倒序）或 name
+//	limit  返回数量，默认 50，最大 200
+func (h *PostHandler) GetTags(c *gin.Context) {
+	limit := defaultTagLimit
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			c.Error(apperr.InvalidQuery("limit 必须是正整数"))
+			return
+		}
+		limit = min(n, maxTagLimit)
+	}
+
+	var byName bool
+	switch c.DefaultQuery("sort", "count") {
+	case "count":
+	case "name":
+		byName = true
+	default:
+		c.Error(apperr.InvalidQuery("sort 必须是 count 或 name"))
+		return
+	}
+
+	tags, err := h.posts.TagCloud(c.Request.Context(), limit, byName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tags": tags,
+	})
+}
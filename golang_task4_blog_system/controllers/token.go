@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"context"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// refreshRequest 刷新令牌与退出登录的请求体
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// RefreshToken 使用刷新令牌换取新的访问令牌，并轮换刷新令牌
+func (h *UserHandler) RefreshToken(c *gin.Context) {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	user, refreshToken, err := h.users.RefreshToken(c.Request.Context(), req.RefreshToken)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	accessToken, expiresAt, err := middleware.GenerateToken(user)
+	if err != nil {
+		c.Error(apperr.Internal("生成令牌失败", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, tokenResponse(accessToken, expiresAt, refreshToken))
+}
+
+// Logout 吊销刷新令牌所在的整个令牌族
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req refreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	if err := h.users.RevokeRefreshToken(c.Request.Context(), req.RefreshToken); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "已退出登录",
+	})
+}
+
+// issueTokenPair 登录成功后签发访问令牌和新令牌族的刷新令牌
+func issueTokenPair(ctx context.Context, users service.UserService, user *models.User) (gin.H, error) {
+	accessToken, expiresAt, err := middleware.GenerateToken(user)
+	if err != nil {
+		return nil, apperr.Internal("生成令牌失败", err)
+	}
+
+	refreshToken, err := users.IssueRefreshToken(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return tokenResponse(accessToken, expiresAt, refreshToken), nil
+}
+
+func tokenResponse(accessToken string, expiresAt time.Time, refreshToken string) gin.H {
+	return gin.H{
+		"access_token":  accessToken,
+		"token_type":    "Bearer",
+		"expires_in":    int64(time.Until(expiresAt).Seconds()),
+		"refresh_token": refreshToken,
+	}
+}
@@ -1,58 +1,170 @@
 package controllers
 
 import (
-	"golang_task4_blog_system/database"
-	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/service"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
+// UserHandler 注册、登录、邮箱验证、找回密码和用户管理接口
+type UserHandler struct {
+	users    service.UserService
+	accounts service.AccountService
+}
+
+// NewUserHandler 创建用户接口处理器
+func NewUserHandler(users service.UserService, accounts service.AccountService) *UserHandler {
+	return &UserHandler{users: users, accounts: accounts}
+}
+
 // 注册
-func Register(c *gin.Context) {
-	var user models.User
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+func (h *UserHandler) Register(c *gin.Context) {
+	// User.Password 不参与 JSON 序列化，注册时需单独绑定
+	var input struct {
+		Username string `json:"username" binding:"required"`
+		Email    string `json:"email" binding:"required,email"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	// 加密密码
-	if err := user.HashPassword(); err != nil {
-		c.JSON(500, gin.H{"error": "密码加密失败"})
+	user, err := h.users.Register(c.Request.Context(), input.Username, input.Email, input.Password)
+	if err != nil {
+		c.Error(err)
 		return
 	}
 
-	// 创建用户
-	if err := database.DB.Create(&user).Error; err != nil {
-		c.JSON(500, gin.H{"error": "创建用户失败"})
-		return
+	// 验证邮件发送失败不影响注册，用户可以登录后重新发送
+	if err := h.accounts.SendVerification(c.Request.Context(), user); err != nil {
+		slog.ErrorContext(c.Request.Context(), "failed to send verification mail", "user_id", user.ID, "error", err)
 	}
 
-	c.JSON(200, gin.H{"message": "注册成功"})
+	c.JSON(200, gin.H{"message": "注册成功，请查收验证邮件"})
 }
 
-// 登录（返回成功信息，实际认证通过 BasicAuth）
-func Login(c *gin.Context) {
+// 登录（校验用户名密码，签发 JWT 访问令牌）
+func (h *UserHandler) Login(c *gin.Context) {
 	var input struct {
-		Username string `json:"username"`
-		Password string `json:"password"`
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
 	// 验证用户
-	var user models.User
-	if err := database.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
-		c.JSON(401, gin.H{"error": "用户不存在"})
+	user, err := h.users.Authenticate(c.Request.Context(), input.Username, input.Password)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	// 签发访问令牌和刷新令牌
+	resp, err := issueTokenPair(c.Request.Context(), h.users, user)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp["message"] = "登录成功"
+	resp["user"] = user
+	c.JSON(200, resp)
+}
+
+// UpdateUserRole 修改用户角色（需要 users:manage 权限）
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	var input struct {
+		Role string `json:"role" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	id, ok := idParam(c, "id", apperr.NotFound(apperr.CodeUserNotFound, "用户不存在"))
+	if !ok {
+		return
+	}
+
+	user, err := h.users.UpdateRole(c.Request.Context(), id, input.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "角色修改成功", "user": user})
+}
+
+// VerifyEmail 使用邮件中的令牌验证邮箱
+func (h *UserHandler) VerifyEmail(c *gin.Context) {
+	var input struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	user, err := h.accounts.VerifyEmail(c.Request.Context(), input.Token)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "邮箱验证成功", "user": user})
+}
+
+// ResendVerification 重新向当前用户的邮箱发送验证邮件，之前的验证链接随之失效
+func (h *UserHandler) ResendVerification(c *gin.Context) {
+	if err := h.accounts.SendVerification(c.Request.Context(), middleware.GetCurrentUser(c)); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "验证邮件已发送"})
+}
+
+// ForgotPassword 发送密码重置邮件，无论邮箱是否注册都返回相同的响应
+func (h *UserHandler) ForgotPassword(c *gin.Context) {
+	var input struct {
+		Email string `json:"email" binding:"required,email"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
+		return
+	}
+
+	if err := h.accounts.RequestPasswordReset(c.Request.Context(), input.Email); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(200, gin.H{"message": "如果该邮箱已注册，重置密码的邮件将很快送达"})
+}
+
+// ResetPassword 使用邮件中的令牌设置新密码，成功后需要重新登录
+func (h *UserHandler) ResetPassword(c *gin.Context) {
+	var input struct {
+		Token    string `json:"token" binding:"required"`
+		Password string `json:"password" binding:"required,min=6"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.Error(apperr.FromBinding(err))
 		return
 	}
 
-	if !user.CheckPassword(input.Password) {
-		c.JSON(401, gin.H{"error": "密码错误"})
+	if err := h.accounts.ResetPassword(c.Request.Context(), input.Token, input.Password); err != nil {
+		c.Error(err)
 		return
 	}
 
-	c.JSON(200, gin.H{"message": "登录成功，使用 Basic Auth 访问受保护接口"})
+	c.JSON(200, gin.H{"message": "密码已重置，请重新登录"})
 }
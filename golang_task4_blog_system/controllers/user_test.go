@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+const testJWTSecret = "controllers-test-secret-0123456789abcdef"
+
+// userFixture 基于内存仓储的用户接口，GET /api/me 挂在认证中间件之后，返回当前用户
+type userFixture struct {
+	users  *memUsers
+	tokens *memRefreshTokens
+	router *gin.Engine
+}
+
+func newUserFixture(t *testing.T) *userFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	middleware.SetJWTSecret(testJWTSecret)
+
+	f := &userFixture{users: &memUsers{}, tokens: &memRefreshTokens{}}
+	users := service.NewUserService(f.users, f.tokens, nil)
+	accounts := service.NewAccountService(f.users, &memUserTokens{}, &outbox{}, service.AccountOptions{
+		BaseURL:          "http://blog.test",
+		VerificationTTL:  time.Hour,
+		PasswordResetTTL: time.Hour,
+	})
+	h := NewUserHandler(users, accounts)
+
+	f.router = gin.New()
+	f.router.Use(middleware.Errors())
+	f.router.POST("/api/register", h.Register)
+	f.router.POST("/api/login", h.Login)
+	f.router.POST("/api/token/refresh", h.RefreshToken)
+	f.router.POST("/api/logout", h.Logout)
+	f.router.GET("/api/me", middleware.Auth(users), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user": middleware.GetCurrentUser(c)})
+	})
+	return f
+}
+
+// testPasswordHash 测试用户密码 secret123 的哈希，bcrypt 较慢，只计算一次
+var testPasswordHash = sync.OnceValue(func() string {
+	user := models.User{Password: "secret123"}
+	if err := user.HashPassword(); err != nil {
+		panic(err)
+	}
+	return user.Password
+})
+
+// addUser 添加邮箱已验证的用户，密码为 secret123
+func addUser(t *testing.T, users *memUsers, username, role string) *models.User {
+	t.Helper()
+	now := time.Now()
+	return users.add(&models.User{
+		Username:        username,
+		Email:           username + "@example.com",
+		Password:        testPasswordHash(),
+		Role:            role,
+		EmailVerifiedAt: &now,
+	})
+}
+
+// login 登录并返回刷新令牌
+func (f *userFixture) login(t *testing.T, username string) string {
+	t.Helper()
+	code, resp := doJSON(t, f.router, "POST", "/api/login", "", gin.H{"username": username, "password": "secret123"})
+	if code != http.StatusOK {
+		t.Fatalf("login: %d %v", code, resp)
+	}
+	return resp["refresh_token"].(string)
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       gin.H
+		wantStatus int
+		wantCode   string
+	}{
+		{"ok", gin.H{"username": "alice", "email": "alice@example.com", "password": "secret123"}, http.StatusOK, ""},
+		{"username taken", gin.H{"username": "bob", "email": "other@example.com", "password": "secret123"}, http.StatusConflict, apperr.CodeUserExists},
+		{"email taken", gin.H{"username": "other", "email": "bob@example.com", "password": "secret123"}, http.StatusConflict, apperr.CodeUserExists},
+		{"reserved name", gin.H{"username": "Deleted-7", "email": "d@example.com", "password": "secret123"}, http.StatusBadRequest, apperr.CodeValidationFailed},
+		{"short password", gin.H{"username": "carol", "email": "carol@example.com", "password": "123"}, http.StatusBadRequest, apperr.CodeValidationFailed},
+		{"invalid email", gin.H{"username": "carol", "email": "carol", "password": "secret123"}, http.StatusBadRequest, apperr.CodeValidationFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			addUser(t, f.users, "bob", models.RoleAuthor)
+
+			code, resp := doJSON(t, f.router, "POST", "/api/register", "", tt.body)
+			if code != tt.wantStatus {
+				t.Fatalf("register: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" && resp["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+			}
+			if tt.wantCode == "" {
+				if _, err := f.users.FindByUsername(t.Context(), "alice"); err != nil {
+					t.Errorf("registered user not stored: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name       string
+		username   string
+		password   string
+		wantStatus int
+		wantCode   string
+	}{
+		{"ok", "alice", "secret123", http.StatusOK, ""},
+		{"wrong password", "alice", "wrong-password", http.StatusUnauthorized, apperr.CodeInvalidCredentials},
+		{"unknown user", "nobody", "secret123", http.StatusUnauthorized, apperr.CodeInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			addUser(t, f.users, "alice", models.RoleAuthor)
+
+			code, resp := doJSON(t, f.router, "POST", "/api/login", "", gin.H{"username": tt.username, "password": tt.password})
+			if code != tt.wantStatus {
+				t.Fatalf("login: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" {
+				if resp["code"] != tt.wantCode {
+					t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+				}
+				return
+			}
+
+			access, _ := resp["access_token"].(string)
+			if access == "" || resp["refresh_token"] == "" {
+				t.Fatalf("login response missing tokens: %v", resp)
+			}
+			if code, resp := doJSON(t, f.router, "GET", "/api/me", access, nil); code != http.StatusOK {
+				t.Errorf("me with issued token: %d %v", code, resp)
+			}
+		})
+	}
+}
+
+// TestLoginFailuresIndistinguishable 用户名不存在和密码错误的响应相同，无法据此判断用户名是否已注册
+func TestLoginFailuresIndistinguishable(t *testing.T) {
+	f := newUserFixture(t)
+	addUser(t, f.users, "alice", models.RoleAuthor)
+
+	_, wrongPassword := doJSON(t, f.router, "POST", "/api/login", "", gin.H{"username": "alice", "password": "wrong-password"})
+	_, unknownUser := doJSON(t, f.router, "POST", "/api/login", "", gin.H{"username": "nobody", "password": "wrong-password"})
+	delete(wrongPassword, "request_id")
+	delete(unknownUser, "request_id")
+	if !reflect.DeepEqual(wrongPassword, unknownUser) {
+		t.Errorf("wrong password response %v differs from unknown user response %v", wrongPassword, unknownUser)
+	}
+}
+
+// TestLoginRequiresFields 缺少用户名或密码时返回字段错误，不进行认证
+func TestLoginRequiresFields(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  gin.H
+		field string
+	}{
+		{"missing username", gin.H{"password": "secret123"}, "username"},
+		{"missing password", gin.H{"username": "alice"}, "password"},
+		{"empty password", gin.H{"username": "alice", "password": ""}, "password"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			addUser(t, f.users, "alice", models.RoleAuthor)
+
+			code, resp := doJSON(t, f.router, "POST", "/api/login", "", tt.body)
+			if code != http.StatusBadRequest || resp["code"] != apperr.CodeValidationFailed {
+				t.Fatalf("login: %d %v, want 400 %s", code, resp, apperr.CodeValidationFailed)
+			}
+			fields, _ := resp["fields"].([]any)
+			if len(fields) != 1 || fields[0].(map[string]any)["field"] != tt.field {
+				t.Errorf("fields = %v, want a single error on %s", resp["fields"], tt.field)
+			}
+		})
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	tests := []struct {
+		name string
+		// prepare 在登录之后执行，返回本次刷新使用的令牌
+		prepare     func(t *testing.T, f *userFixture, token string) string
+		wantStatus  int
+		wantCode    string
+		wantRevoked int // 请求后已吊销的令牌数
+	}{
+		{
+			name:        "rotates token",
+			prepare:     func(t *testing.T, f *userFixture, token string) string { return token },
+			wantStatus:  http.StatusOK,
+			wantRevoked: 1,
+		},
+		{
+			name:       "unknown token",
+			prepare:    func(t *testing.T, f *userFixture, token string) string { return "not-a-token" },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeRefreshTokenInvalid,
+		},
+		{
+			name: "rotated token reused revokes family",
+			prepare: func(t *testing.T, f *userFixture, token string) string {
+				if code, resp := doJSON(t, f.router, "POST", "/api/token/refresh", "", gin.H{"refresh_token": token}); code != http.StatusOK {
+					t.Fatalf("first refresh: %d %v", code, resp)
+				}
+				return token
+			},
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    apperr.CodeRefreshTokenReused,
+			wantRevoked: 2,
+		},
+		{
+			name: "after logout",
+			prepare: func(t *testing.T, f *userFixture, token string) string {
+				if code, resp := doJSON(t, f.router, "POST", "/api/logout", "", gin.H{"refresh_token": token}); code != http.StatusOK {
+					t.Fatalf("logout: %d %v", code, resp)
+				}
+				return token
+			},
+			wantStatus:  http.StatusUnauthorized,
+			wantCode:    apperr.CodeRefreshTokenReused,
+			wantRevoked: 1,
+		},
+		{
+			name: "expired",
+			prepare: func(t *testing.T, f *userFixture, token string) string {
+				f.tokens.tokens[0].ExpiresAt = time.Now().Add(-time.Minute)
+				return token
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeRefreshTokenInvalid,
+		},
+		{
+			name: "deleted user",
+			prepare: func(t *testing.T, f *userFixture, token string) string {
+				now := time.Now()
+				f.users.users[0].DeletedAt = &now
+				return token
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeRefreshTokenInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			addUser(t, f.users, "alice", models.RoleAuthor)
+			token := tt.prepare(t, f, f.login(t, "alice"))
+
+			code, resp := doJSON(t, f.router, "POST", "/api/token/refresh", "", gin.H{"refresh_token": token})
+			if code != tt.wantStatus {
+				t.Fatalf("refresh: %d %v, want %d", code, resp, tt.wantStatus)
+			}
+			if tt.wantCode != "" && resp["code"] != tt.wantCode {
+				t.Errorf("code = %v, want %s", resp["code"], tt.wantCode)
+			}
+			if tt.wantCode == "" && (resp["access_token"] == "" || resp["refresh_token"] == token) {
+				t.Errorf("refresh did not issue new tokens: %v", resp)
+			}
+			if n := f.tokens.revoked(); n != tt.wantRevoked {
+				t.Errorf("revoked tokens = %d, want %d", n, tt.wantRevoked)
+			}
+		})
+	}
+}
+
+func TestLogoutUnknownToken(t *testing.T) {
+	f := newUserFixture(t)
+	if code, resp := doJSON(t, f.router, "POST", "/api/logout", "", gin.H{"refresh_token": "not-a-token"}); code != http.StatusOK {
+		t.Errorf("logout with unknown token: %d %v, want 200", code, resp)
+	}
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	basic := func(username, password string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.SetBasicAuth(username, password)
+		return req.Header.Get("Authorization")
+	}
+
+	tests := []struct {
+		name string
+		// header 返回 Authorization 请求头，user 为 alice
+		header     func(t *testing.T, f *userFixture, user *models.User) string
+		wantStatus int
+		wantCode   string
+		wantScheme string // WWW-Authenticate 的认证方式
+	}{
+		{
+			name: "bearer",
+			header: func(t *testing.T, f *userFixture, user *models.User) string {
+				return "Bearer " + accessToken(t, user)
+			},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "missing",
+			header:     func(t *testing.T, f *userFixture, user *models.User) string { return "" },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeTokenMissing,
+			wantScheme: "Bearer",
+		},
+		{
+			name:       "malformed bearer",
+			header:     func(t *testing.T, f *userFixture, user *models.User) string { return "Bearer not.a.jwt" },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeTokenInvalid,
+			wantScheme: "Bearer",
+		},
+		{
+			name: "bearer for unknown user",
+			header: func(t *testing.T, f *userFixture, user *models.User) string {
+				return "Bearer " + accessToken(t, &models.User{ID: 99, Username: "ghost"})
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeTokenInvalid,
+			wantScheme: "Bearer",
+		},
+		{
+			name: "bearer for deleted user",
+			header: func(t *testing.T, f *userFixture, user *models.User) string {
+				now := time.Now()
+				f.users.users[0].DeletedAt = &now
+				return "Bearer " + accessToken(t, user)
+			},
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeTokenInvalid,
+			wantScheme: "Bearer",
+		},
+		{
+			name:       "basic",
+			header:     func(t *testing.T, f *userFixture, user *models.User) string { return basic("alice", "secret123") },
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "basic wrong password",
+			header:     func(t *testing.T, f *userFixture, user *models.User) string { return basic("alice", "wrong") },
+			wantStatus: http.StatusUnauthorized,
+			wantCode:   apperr.CodeInvalidCredentials,
+			wantScheme: "Basic",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newUserFixture(t)
+			user := addUser(t, f.users, "alice", models.RoleAuthor)
+
+			req := httptest.NewRequest("GET", "/api/me", nil)
+			if header := tt.header(t, f, user); header != "" {
+				req.Header.Set("Authorization", header)
+			}
+			w := httptest.NewRecorder()
+			f.router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("me: %d %s, want %d", w.Code, w.Body, tt.wantStatus)
+			}
+			if tt.wantCode != "" && !strings.Contains(w.Body.String(), `"code":"`+tt.wantCode+`"`) {
+				t.Errorf("body = %s, want code %s", w.Body, tt.wantCode)
+			}
+			if tt.wantCode == "" && !strings.Contains(w.Body.String(), `"username":"alice"`) {
+				t.Errorf("body = %s, want current user alice", w.Body)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, tt.wantScheme) || (tt.wantScheme == "") != (got == "") {
+				t.Errorf("WWW-Authenticate = %q, want scheme %q", got, tt.wantScheme)
+			}
+		})
+	}
+}
+
+// accessToken 为用户签发访问令牌
+func accessToken(t *testing.T, user *models.User) string {
+	t.Helper()
+	token, _, err := middleware.GenerateToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
@@ -1,75 +1,190 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"log/slog"
+	"net/url"
 	"time"
 
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var DB *gorm.DB
 
-// MySQLConfig 包含MySQL连接配置
-type MySQLConfig struct {
+// slowQueryThreshold 耗时超过该值的 SQL 以 warn 级别记录
+const slowQueryThreshold = 200 * time.Millisecond
+
+// 支持的数据库驱动
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Config 数据库连接配置，Driver 决定使用的数据库；
+// 指定 DSN 时直接使用，否则由其余字段拼接。SQLite 的 Database 为文件路径，":memory:" 表示内存数据库
+type Config struct {
+	Driver          string
+	DSN             string
 	Host            string
 	Port            string
 	User            string
 	Password        string
 	Database        string
+	SSLMode         string // 仅 PostgreSQL，默认 disable
 	MaxIdleConns    int
 	MaxOpenConns    int
 	ConnMaxLifetime time.Duration
 }
 
-func InitDB(cfg *MySQLConfig) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
-		cfg.User,
-		cfg.Password,
-		cfg.Host,
-		cfg.Port,
-		cfg.Database,
-	)
-	log.Printf("Connecting to MySQL database: %s@%s:%s/%s", 
-		cfg.User, cfg.Host, cfg.Port, cfg.Database)
-
-	var err error
-	DB, err = gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// Dialector 根据驱动创建 GORM Dialector
+func (cfg *Config) Dialector() (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL:
+		return mysql.Open(cfg.dsn()), nil
+	case DriverPostgres:
+		return postgres.Open(cfg.dsn()), nil
+	case DriverSQLite:
+		if cfg.DSN == "" && cfg.Database == "" {
+			return nil, errors.New("sqlite database path is required")
+		}
+		return sqlite.Open(cfg.dsn()), nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver %q", cfg.Driver)
+	}
+}
+
+func (cfg *Config) dsn() string {
+	if cfg.DSN != "" {
+		return cfg.DSN
+	}
+
+	switch cfg.Driver {
+	case DriverPostgres:
+		sslMode := cfg.SSLMode
+		if sslMode == "" {
+			sslMode = "disable"
+		}
+		u := url.URL{
+			Scheme:   "postgres",
+			User:     url.UserPassword(cfg.User, cfg.Password),
+			Host:     cfg.Host + ":" + cfg.Port,
+			Path:     cfg.Database,
+			RawQuery: url.Values{"sslmode": {sslMode}}.Encode(),
+		}
+		return u.String()
+	case DriverSQLite:
+		// 开启外键约束（级联删除依赖它），写锁冲突时等待而不是立即失败
+		return "file:" + cfg.Database + "?_foreign_keys=on&_busy_timeout=5000"
+	default:
+		return fmt.Sprintf("%s:%s@tcp(%s:%s)/%s?charset=utf8mb4&parseTime=True&loc=Local",
+			cfg.User,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.Database,
+		)
+	}
+}
+
+// target 用于日志的连接目标，不包含密码
+func (cfg *Config) target() string {
+	switch {
+	case cfg.DSN != "":
+		return cfg.Driver + " (custom DSN)"
+	case cfg.Driver == DriverSQLite:
+		return cfg.Database
+	default:
+		return fmt.Sprintf("%s@%s:%s/%s", cfg.User, cfg.Host, cfg.Port, cfg.Database)
+	}
+}
+
+// Open 按配置打开数据库连接、设置连接池并测试连接
+func Open(cfg *Config) (*gorm.DB, error) {
+	dialector, err := cfg.Dialector()
 	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
+		return nil, err
 	}
 
-	log.Println("✅ MySQL connection established successfully")
+	log.Printf("Connecting to %s database: %s", cfg.Driver, cfg.target())
 
-	// Auto migrate tables
-	sqlDB, err := DB.DB()
+	// TranslateError 把唯一约束等数据库错误转换为 gorm.ErrDuplicatedKey 等通用错误
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: newLogger(), TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("connect to database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
 	if err != nil {
-		log.Fatal("Failed to get generic database object:", err)
+		return nil, fmt.Errorf("get generic database object: %w", err)
 	}
 
-	// 设置连接池
-	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
-	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
-	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	// 设置连接池；SQLite 内存数据库随最后一个连接关闭而销毁，只保留一个长期连接
+	if cfg.Driver == DriverSQLite && cfg.Database == ":memory:" {
+		sqlDB.SetMaxOpenConns(1)
+	} else {
+		sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+		sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+		sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
 
 	// 测试连接
 	if err := sqlDB.Ping(); err != nil {
-		log.Fatal("Failed to ping database:", err)
+		sqlDB.Close()
+		return nil, fmt.Errorf("ping database: %w", err)
 	}
 
-	log.Println("Database migrated successfully")
+	return db, nil
+}
+
+// newLogger 通过 slog 输出 SQL 日志，使用带请求上下文的 DB 时会带上请求ID和用户ID。
+// 默认只记录出错和慢的 SQL，且不输出参数值（避免密码哈希、令牌等进入日志）；
+// 日志级别为 debug 时记录全部 SQL 及参数
+func newLogger() logger.Interface {
+	debug := slog.Default().Enabled(context.Background(), slog.LevelDebug)
+	level := logger.Warn
+	if debug {
+		level = logger.Info
+	}
+	return logger.NewSlogLogger(slog.Default(), logger.Config{
+		SlowThreshold:             slowQueryThreshold,
+		LogLevel:                  level,
+		IgnoreRecordNotFoundError: true,
+		ParameterizedQueries:      !debug,
+	})
+}
+
+// InitDB 打开数据库连接并设置为全局 DB
+func InitDB(cfg *Config) error {
+	db, err := Open(cfg)
+	if err != nil {
+		return err
+	}
+	DB = db
+
+	log.Printf("✅ %s connection established successfully", cfg.Driver)
+	return nil
 }
 
 // 关闭数据库连接
-func CloseDB() {
-	if DB != nil {
-		sqlDB, err := DB.DB()
-		if err != nil {
-			log.Println("Failed to get generic database object:", err)
-			return
-		}
-		sqlDB.Close()
-		log.Println("Database connection closed")
+func CloseDB() error {
+	if DB == nil {
+		return nil
 	}
-}
\ No newline at end of file
+	sqlDB, err := DB.DB()
+	if err != nil {
+		return fmt.Errorf("get generic database object: %w", err)
+	}
+	if err := sqlDB.Close(); err != nil {
+		return err
+	}
+	log.Println("Database connection closed")
+	return nil
+}
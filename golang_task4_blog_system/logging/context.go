@@ -0,0 +1,40 @@
+package logging
+
+import "context"
+
+type requestInfoKey struct{}
+
+// requestInfo 请求级别的日志字段。认证中间件在日志中间件之后执行，
+// 因此在上下文中保存指针，认证通过后再填入用户ID
+type requestInfo struct {
+	requestID string
+	userID    uint
+}
+
+// WithRequestID 返回带有请求ID的上下文，之后用该上下文记录的日志都会带上请求ID和用户ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, &requestInfo{requestID: requestID})
+}
+
+// RequestID 返回上下文中的请求ID，不存在时返回空字符串
+func RequestID(ctx context.Context) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.requestID
+	}
+	return ""
+}
+
+// SetUserID 记录当前请求的用户ID，上下文不是由 WithRequestID 创建时忽略
+func SetUserID(ctx context.Context, userID uint) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.userID = userID
+	}
+}
+
+// UserID 返回上下文中的用户ID，未认证时返回 0
+func UserID(ctx context.Context) uint {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.userID
+	}
+	return 0
+}
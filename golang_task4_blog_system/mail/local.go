@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer 把每封邮件写入目录中的 .eml 文件，用于开发和测试环境查看邮件内容，
+// 正文不做 quoted-printable 编码，可以直接从文件中复制链接
+type FileMailer struct {
+	dir  string
+	from *mail.Address
+}
+
+// NewFileMailer 创建文件发送器，目录不存在时自动创建
+func NewFileMailer(dir string, from *mail.Address) (*FileMailer, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("mail directory is required")
+	}
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, err := compose(m.from, msg, now, encoding8Bit)
+	if err != nil {
+		return err
+	}
+
+	// 文件名按时间排序，随机后缀避免同一时刻的邮件互相覆盖
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return err
+	}
+	name := now.UTC().Format("20060102T150405.000000000") + "-" + hex.EncodeToString(suffix) + ".eml"
+	path := filepath.Join(m.dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write mail: %w", err)
+	}
+	slog.InfoContext(ctx, "mail written", "to", msg.To, "subject", msg.Subject, "path", path)
+	return nil
+}
+
+// LogMailer 不发送邮件，只把邮件内容写入日志，正文中包含令牌，只应在开发环境使用
+type LogMailer struct {
+	from *mail.Address
+}
+
+// NewLogMailer 创建日志发送器
+func NewLogMailer(from *mail.Address) *LogMailer {
+	return &LogMailer{from: from}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if _, err := mail.ParseAddress(msg.To); err != nil {
+		return fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+	slog.InfoContext(ctx, "mail", "from", m.from.String(), "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
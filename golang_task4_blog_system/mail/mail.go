@@ -0,0 +1,106 @@
+// Package mail 发送系统邮件。Mailer 是可替换的发送接口：
+// 生产环境使用 SMTPMailer，开发和测试环境可以把邮件写入文件或日志
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// 发送方式
+const (
+	DriverSMTP = "smtp"
+	DriverFile = "file"
+	DriverLog  = "log"
+)
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config 邮件配置，Driver 决定使用的 Mailer；Dir 仅用于 file，Host 等仅用于 smtp
+type Config struct {
+	Driver   string
+	From     string // 发件人，如 "Blog <no-reply@example.com>"
+	Dir      string
+	Host     string
+	Port     int
+	Username string // 为空时不进行 SMTP 认证
+	Password string
+}
+
+// New 根据配置创建 Mailer
+func New(cfg Config) (Mailer, error) {
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mail sender %q: %w", cfg.From, err)
+	}
+
+	switch cfg.Driver {
+	case DriverSMTP:
+		return NewSMTPMailer(cfg.Host, cfg.Port, cfg.Username, cfg.Password, from), nil
+	case DriverFile:
+		return NewFileMailer(cfg.Dir, from)
+	case DriverLog:
+		return NewLogMailer(from), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver %q", cfg.Driver)
+	}
+}
+
+// 正文的传输编码
+const (
+	// encodingQuotedPrintable 只含 ASCII 字符，不依赖 SMTP 服务器支持 8BITMIME，用于实际发送
+	encodingQuotedPrintable = "quoted-printable"
+	// encoding8Bit 正文原样写入（换行统一为 CRLF），保存的 .eml 文件可以直接阅读和复制链接
+	encoding8Bit = "8bit"
+)
+
+// compose 生成 RFC 5322 格式的邮件，主题按 RFC 2047 编码，正文为 UTF-8，
+// 传输编码为 encodingQuotedPrintable 或 encoding8Bit
+func compose(from *mail.Address, msg Message, now time.Time, encoding string) ([]byte, error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q: %w", msg.To, err)
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	fmt.Fprintf(&buf, "Content-Transfer-Encoding: %s\r\n\r\n", encoding)
+
+	switch encoding {
+	case encoding8Bit:
+		body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+		buf.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	case encodingQuotedPrintable:
+		qp := quotedprintable.NewWriter(&buf)
+		if _, err := qp.Write([]byte(msg.Body)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported transfer encoding %q", encoding)
+	}
+	return buf.Bytes(), nil
+}
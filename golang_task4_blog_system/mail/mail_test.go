@@ -0,0 +1,127 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLink = "http://localhost:8080/verify-email?token=Zm9vYmFyYmF6cXV4_-0123456789abcdefghijklmnopqrstuvwxyz"
+
+var testMessage = Message{
+	To:      "Alice <alice@example.com>",
+	Subject: "请验证您的邮箱",
+	Body:    "您好，alice：\n\n请打开以下链接验证邮箱：\n" + testLink + "\n\n链接 48 小时内有效。\n",
+}
+
+// readMessage 解析邮件并按 Content-Transfer-Encoding 解码正文
+func readMessage(t *testing.T, data []byte) (*mail.Message, string) {
+	t.Helper()
+	m, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	var body io.Reader = m.Body
+	switch enc := m.Header.Get("Content-Transfer-Encoding"); enc {
+	case encodingQuotedPrintable:
+		body = quotedprintable.NewReader(m.Body)
+	case encoding8Bit:
+	default:
+		t.Fatalf("unexpected Content-Transfer-Encoding %q", enc)
+	}
+	decoded, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	return m, strings.ReplaceAll(string(decoded), "\r\n", "\n")
+}
+
+func TestCompose(t *testing.T) {
+	from := &mail.Address{Name: "Blog", Address: "no-reply@example.com"}
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, encoding := range []string{encoding8Bit, encodingQuotedPrintable} {
+		t.Run(encoding, func(t *testing.T) {
+			data, err := compose(from, testMessage, now, encoding)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, body := readMessage(t, data)
+
+			subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+			if err != nil || subject != testMessage.Subject {
+				t.Errorf("subject = %q (%v), want %q", subject, err, testMessage.Subject)
+			}
+			if to, err := m.Header.AddressList("To"); err != nil || len(to) != 1 || to[0].Address != "alice@example.com" {
+				t.Errorf("To = %v (%v)", to, err)
+			}
+			if got := m.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+				t.Errorf("Content-Type = %q", got)
+			}
+			if body != testMessage.Body {
+				t.Errorf("decoded body = %q, want %q", body, testMessage.Body)
+			}
+			if !strings.Contains(body, testLink) {
+				t.Errorf("decoded body does not contain the token URL")
+			}
+		})
+	}
+}
+
+func TestCompose8BitKeepsLinkReadable(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@example.com"}
+	data, err := compose(from, testMessage, time.Now(), encoding8Bit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := string(data)
+	if !strings.Contains(raw, testLink+"\r\n") {
+		t.Errorf("raw message does not contain the token URL verbatim:\n%s", raw)
+	}
+	if strings.Contains(raw, "=3D") {
+		t.Errorf("raw message is quoted-printable encoded:\n%s", raw)
+	}
+	if strings.Contains(strings.ReplaceAll(raw, "\r\n", ""), "\n") {
+		t.Error("raw message contains bare LF line endings")
+	}
+}
+
+func TestComposeRejectsInvalidRecipient(t *testing.T) {
+	from := &mail.Address{Address: "no-reply@example.com"}
+	if _, err := compose(from, Message{To: "not an address"}, time.Now(), encoding8Bit); err == nil {
+		t.Error("compose accepted an invalid recipient")
+	}
+}
+
+func TestFileMailer(t *testing.T) {
+	dir := t.TempDir()
+	m, err := NewFileMailer(dir, &mail.Address{Name: "Blog", Address: "no-reply@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("mail files = %v (%v), want exactly one", files, err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), testLink) {
+		t.Errorf("%s does not contain the token URL verbatim", files[0])
+	}
+	if _, body := readMessage(t, data); body != testMessage.Body {
+		t.Errorf("decoded body = %q, want %q", body, testMessage.Body)
+	}
+}
@@ -1,65 +1,268 @@
 package main
 
 import (
+	"context"
+	"flag"
+	"golang_task4_blog_system/config"
 	"golang_task4_blog_system/controllers"
 	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/logging"
+	"golang_task4_blog_system/mail"
+	"golang_task4_blog_system/metrics"
 	"golang_task4_blog_system/middleware"
+	"golang_task4_blog_system/migrations"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/ratelimit"
+	"golang_task4_blog_system/repository"
+	"golang_task4_blog_system/scheduler"
+	"golang_task4_blog_system/service"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// JWTSecret 用于签名JWT令牌
-const JWTSecret = "your-super-secret-jwt-key-change-in-production"
-
 func main() {
-  // 初始化数据库连接
-	dbConfig := &database.MySQLConfig{
-		Host:     "localhost",
-		Port:     "3306",
-		User:     "root",
-		Password: "wilson1234",
-		Database: "blog_system",
-		MaxIdleConns:    100,
-	    MaxOpenConns:100,
+	defaultConfig := os.Getenv("BLOG_CONFIG")
+	if defaultConfig == "" {
+		defaultConfig = "config.yaml"
+	}
+	configPath := flag.String("config", defaultConfig, "配置文件路径（YAML 或 TOML），也可通过 BLOG_CONFIG 指定")
+	flag.Parse()
+
+	// 加载配置
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatal("Failed to load config:", err)
+	}
+	if err := logging.Setup(os.Stdout, cfg.Log.Format, cfg.Log.Level); err != nil {
+		log.Fatal("Failed to set up logging:", err)
+	}
+	log.Printf("Loaded %s config from %s", cfg.Env, *configPath)
+
+	// 初始化数据库连接
+	if err := database.InitDB(cfg.DBConfig()); err != nil {
+		log.Fatal("Failed to initialize database:", err)
+	}
+
+	// migrate 子命令：go run . [-config 文件] migrate up|down [n]|to <version>|status
+	if args := flag.Args(); len(args) > 0 && args[0] == "migrate" {
+		err := runMigrate(args[1:])
+		database.CloseDB()
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// 数据库结构必须是最新版本
+	pending, err := migrations.Pending(database.DB)
+	if err != nil {
+		log.Fatal("Failed to check migrations:", err)
+	}
+	if len(pending) > 0 {
+		log.Fatalf("Database has %d pending migration(s), run \"migrate up\" first", len(pending))
+	}
+
+	// 导出 SQL 耗时和连接池指标
+	if err := metrics.InstrumentDB(database.DB, cfg.Database.Driver); err != nil {
+		log.Fatal("Failed to instrument database:", err)
+	}
+
+	// 从数据库重建搜索索引
+	if err := models.RebuildSearchIndex(database.DB); err != nil {
+		log.Fatal("Failed to build search index:", err)
+	}
+
+	// 设置JWT签名密钥
+	middleware.SetJWTSecret(cfg.JWT.Secret)
+
+	// 收到 SIGINT/SIGTERM 时开始优雅退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// 限流和登录锁定状态保存在进程内，多实例部署时各实例分别计数
+	limitStore := ratelimit.NewMemoryStore()
+	lockout := ratelimit.NewLockout(limitStore, cfg.RateLimit.Lockout.Policy())
+	loginLimit := middleware.RateLimit(limitStore, "login", cfg.RateLimit.Login.Limit(), middleware.ByIP)
+	writeLimit := middleware.RateLimit(limitStore, "write", cfg.RateLimit.Write.Limit(), middleware.WritesOnly(middleware.ByUser))
+	commentLimit := middleware.RateLimit(limitStore, "comment", cfg.RateLimit.Comment.Limit(), middleware.ByUser)
+	exportLimit := middleware.RateLimit(limitStore, "export", cfg.RateLimit.Export.Limit(), middleware.ByUser)
+
+	// 验证邮件和密码重置邮件
+	mailer, err := mail.New(cfg.MailConfig())
+	if err != nil {
+		log.Fatal("Failed to set up mailer:", err)
+	}
+
+	// 组装仓储、服务和接口处理器
+	userRepo := repository.NewUserRepository(database.DB)
+	postRepo := repository.NewPostRepository(database.DB)
+	commentRepo := repository.NewCommentRepository(database.DB)
+	accounts := service.NewAccountService(userRepo, repository.NewUserTokenRepository(database.DB), mailer, service.AccountOptions{
+		BaseURL:          cfg.Account.BaseURL,
+		VerificationTTL:  time.Duration(cfg.Account.VerificationTTL),
+		PasswordResetTTL: time.Duration(cfg.Account.PasswordResetTTL),
+	})
+	// 登录接口与 Basic 认证共用同一个账户锁定器
+	users := service.NewUserService(userRepo, repository.NewRefreshTokenRepository(database.DB), lockout)
+	userHandler := controllers.NewUserHandler(users, accounts)
+	exportHandler := controllers.NewExportHandler(service.NewExportService(postRepo, commentRepo))
+	postHandler := controllers.NewPostHandler(service.NewPostService(postRepo, commentRepo))
+	commentHandler := controllers.NewCommentHandler(service.NewCommentService(commentRepo, postRepo))
+	healthHandler := controllers.NewHealthHandler(database.DB)
+
+	// 启动定时发布任务，退出时在 HTTP 服务停止后取消
+	workerCtx, cancelWorkers := context.WithCancel(context.Background())
+	defer cancelWorkers()
+	publisherDone := scheduler.StartPostPublisher(workerCtx, postRepo, time.Duration(cfg.Scheduler.PublishInterval))
+
+	gin.SetMode(cfg.Server.Mode)
+	router := gin.New()
+	// 只信任配置的反向代理转发的客户端 IP，否则按 IP 限流可被伪造的 X-Forwarded-For 绕过
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		log.Fatal("Invalid trusted proxies:", err)
 	}
+	router.Use(middleware.Logger(), middleware.Metrics(), gin.Recovery(), middleware.Errors())
+	router.NoRoute(middleware.NotFound)
 
-	database.InitDB(dbConfig)
-	defer database.CloseDB()
+	// 存活和就绪检查，供负载均衡和编排系统探测
+	router.GET("/healthz", controllers.Healthz)
+	router.GET("/readyz", healthHandler.Readyz)
 
-    router := gin.Default()
+	// Prometheus 指标，应只对内网开放
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// 公开路由
 	public := router.Group("/api")
+	public.Use(middleware.OptionalAuth(users))
 	{
-		public.POST("/register", controllers.Register)
-		public.POST("/login", controllers.Login)
-		public.GET("/posts", controllers.GetPosts)
-		public.GET("/posts/:id", controllers.GetPost)
+		public.POST("/register", loginLimit, userHandler.Register)
+		public.POST("/login", loginLimit, userHandler.Login)
+		public.POST("/token/refresh", loginLimit, userHandler.RefreshToken)
+		public.POST("/logout", userHandler.Logout)
+		public.POST("/email/verify", userHandler.VerifyEmail)
+		public.POST("/password/forgot", loginLimit, userHandler.ForgotPassword)
+		public.POST("/password/reset", loginLimit, userHandler.ResetPassword)
+		public.GET("/posts", postHandler.GetPosts)
+		public.GET("/posts/:id", postHandler.GetPost)
+		public.GET("/posts/by-slug/:slug", postHandler.GetPostBySlug)
+		public.GET("/posts/:id/comments", commentHandler.GetPostComments)
+		public.GET("/posts/:id/comments/tree", commentHandler.GetPostCommentTree)
+		public.GET("/users/:username", userHandler.GetUserProfile)
+		public.GET("/search", postHandler.Search)
+		public.GET("/tags", postHandler.GetTags)
+		public.GET("/categories", postHandler.GetCategories)
 	}
 
-	// 需要认证的路由
+	// 需要认证的路由，各路由按权限进一步限制
 	auth := router.Group("/api")
-	auth.Use(middleware.BasicAuth())
+	auth.Use(middleware.Auth(users), writeLimit)
 	{
-		// 文章管理
-		auth.POST("/posts", controllers.CreatePost)
-		auth.PUT("/posts/:id", controllers.UpdatePost)
-		auth.DELETE("/posts/:id", controllers.DeletePost)
-		
-		// 评论管理
-		auth.POST("/comments", controllers.CreateComment)    // 创建评论（需要认证）
-		auth.GET("/comments/:id", controllers.GetComment)    // 获取评论详情（需要认证）
-		auth.PUT("/comments/:id", controllers.UpdateComment) // 更新评论（需要认证+作者权限）
-		auth.DELETE("/comments/:id", controllers.DeleteComment) // 删除评论（需要认证+作者权限）
-		auth.GET("/comments/my", controllers.GetMyComments)  // 获取我的评论（需要认证）
-	}
-
-  // 启动服务器
-	port := ":8080"
-	log.Printf("Server starting on port %s", port)
-	
-	if err := router.Run(port); err != nil {
-		log.Fatal("Failed to start server:", err)
-	}
-}
\ No newline at end of file
+		// 邮箱未验证的用户只能使用不需要权限的接口，如重新发送验证邮件
+		auth.POST("/email/verify/resend", loginLimit, userHandler.ResendVerification)
+
+		// 个人资料（修改邮箱同样会发送验证邮件）、注销账户和导出个人数据
+		auth.GET("/me", userHandler.GetMe)
+		auth.PUT("/me", userHandler.UpdateMe)
+		auth.PUT("/me/password", userHandler.ChangePassword)
+		auth.PUT("/me/email", loginLimit, userHandler.ChangeEmail)
+		auth.DELETE("/me", userHandler.DeleteMe)
+		auth.GET("/me/export", exportLimit, exportHandler.ExportMe)
+
+		// 文章管理（更新、删除限文章作者或拥有 any 权限的用户）
+		auth.POST("/posts", middleware.RequirePermission(models.PermPostsCreate), postHandler.CreatePost)
+		auth.PUT("/posts/:id", middleware.RequirePermission(models.PermPostsUpdateOwn, models.PermPostsUpdateAny), postHandler.UpdatePost)
+		auth.DELETE("/posts/:id", middleware.RequirePermission(models.PermPostsDeleteOwn, models.PermPostsDeleteAny), postHandler.DeletePost)
+		auth.GET("/me/posts", postHandler.GetMyPosts)
+
+		// 评论管理（更新、删除限评论作者或拥有 any 权限的用户）
+		auth.POST("/comments", commentLimit, middleware.RequirePermission(models.PermCommentsCreate), commentHandler.CreateComment)
+		auth.GET("/comments/:id", commentHandler.GetComment)
+		auth.PUT("/comments/:id", middleware.RequirePermission(models.PermCommentsUpdateOwn, models.PermCommentsUpdateAny), commentHandler.UpdateComment)
+		auth.DELETE("/comments/:id", middleware.RequirePermission(models.PermCommentsDeleteOwn, models.PermCommentsDeleteAny), commentHandler.DeleteComment)
+		auth.GET("/comments/my", commentHandler.GetMyComments)
+
+		// 评论审核（文章作者、编辑或管理员）
+		moderate := middleware.RequirePermission(models.PermCommentsModerateOwn, models.PermCommentsModerateAny)
+		auth.PUT("/comments/:id/approve", moderate, commentHandler.ApproveComment)
+		auth.PUT("/comments/:id/reject", moderate, commentHandler.RejectComment)
+		auth.POST("/comments/moderate", moderate, commentHandler.ModerateComments)
+		auth.GET("/moderation/comments", moderate, commentHandler.GetModerationQueue)
+
+		// 分类管理（编辑或管理员）
+		auth.POST("/categories", middleware.RequirePermission(models.PermCategoriesManage), postHandler.CreateCategory)
+		auth.PUT("/categories/:id", middleware.RequirePermission(models.PermCategoriesManage), postHandler.UpdateCategory)
+		auth.DELETE("/categories/:id", middleware.RequirePermission(models.PermCategoriesManage), postHandler.DeleteCategory)
+
+		// 用户管理（管理员）
+		auth.PUT("/users/:id/role", middleware.RequirePermission(models.PermUsersManage), userHandler.UpdateUserRole)
+	}
+
+	// 启动服务器
+	server := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           router,
+		ReadTimeout:       time.Duration(cfg.Server.ReadTimeout),
+		ReadHeaderTimeout: time.Duration(cfg.Server.ReadHeaderTimeout),
+		WriteTimeout:      time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:       time.Duration(cfg.Server.IdleTimeout),
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Printf("Server starting on %s", server.Addr)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// 等待退出信号或服务器异常退出
+	exitCode := 0
+	select {
+	case <-ctx.Done():
+		log.Println("Shutting down server...")
+	case err := <-serverErr:
+		log.Println("Server stopped unexpectedly:", err)
+		exitCode = 1
+	}
+	// 恢复默认信号处理，再次收到信号时立即退出
+	stop()
+
+	if !shutdown(time.Duration(cfg.Server.ShutdownTimeout), server, cancelWorkers, publisherDone, database.CloseDB) {
+		exitCode = 1
+	}
+	log.Println("Server exited")
+	os.Exit(exitCode)
+}
+
+// shutdown 依次停止接收新请求并等待处理中的请求完成、停止后台任务并等待其结束、关闭数据库，
+// 整个过程不超过 timeout，超时的步骤被放弃但数据库总会关闭；全部按期完成时返回 true
+func shutdown(timeout time.Duration, server *http.Server, stopWorkers context.CancelFunc, workersDone <-chan struct{}, closeDB func() error) bool {
+	ok := true
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// 在期限内等待处理中的请求完成，超时后强制关闭剩余连接
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("Graceful shutdown timed out, closing remaining connections:", err)
+		server.Close()
+		ok = false
+	}
+
+	// 等待正在执行的任务结束后再关闭数据库
+	stopWorkers()
+	select {
+	case <-workersDone:
+	case <-ctx.Done():
+		log.Println("Timed out waiting for background workers to stop")
+		ok = false
+	}
+
+	if err := closeDB(); err != nil {
+		log.Println("Failed to close database:", err)
+		ok = false
+	}
+	return ok
+}
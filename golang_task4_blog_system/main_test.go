@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// events 记录关闭过程中各步骤完成的顺序
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(name string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, name)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.list)
+}
+
+// startServer 启动一个处理请求耗时 delay 的服务器，请求开始处理时关闭 started
+func startServer(t *testing.T, ev *events, delay time.Duration) (*http.Server, string, <-chan struct{}) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := make(chan struct{})
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(delay)
+		ev.add("request finished")
+	})}
+	go server.Serve(ln)
+	t.Cleanup(func() { server.Close() })
+	return server, "http://" + ln.Addr().String(), started
+}
+
+// startWorker 模拟后台任务：取消后还需要 delay 才能结束当前的任务
+func startWorker(ev *events, delay time.Duration) (context.CancelFunc, <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		<-ctx.Done()
+		time.Sleep(delay)
+		ev.add("worker stopped")
+	}()
+	return cancel, done
+}
+
+// TestShutdownOrder 处理中的请求完成后停止后台任务，后台任务结束后才关闭数据库
+func TestShutdownOrder(t *testing.T) {
+	ev := &events{}
+	server, url, started := startServer(t, ev, 50*time.Millisecond)
+	go http.Get(url)
+	<-started
+
+	stopWorker, workerDone := startWorker(ev, 50*time.Millisecond)
+	closeDB := func() error {
+		ev.add("db closed")
+		return nil
+	}
+
+	if !shutdown(5*time.Second, server, stopWorker, workerDone, closeDB) {
+		t.Error("shutdown reported a timeout")
+	}
+	want := []string{"request finished", "worker stopped", "db closed"}
+	if got := ev.get(); !slices.Equal(got, want) {
+		t.Errorf("shutdown order = %v, want %v", got, want)
+	}
+}
+
+// TestShutdownWorkerTimeout 后台任务在期限内没有结束时仍然关闭数据库，并报告超时
+func TestShutdownWorkerTimeout(t *testing.T) {
+	ev := &events{}
+	server, _, _ := startServer(t, ev, 0)
+
+	workerDone := make(chan struct{}) // 永远不会结束
+	closeDB := func() error {
+		ev.add("db closed")
+		return nil
+	}
+
+	start := time.Now()
+	if shutdown(50*time.Millisecond, server, func() {}, workerDone, closeDB) {
+		t.Error("shutdown did not report the worker timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("shutdown took %v, want it bounded by the timeout", elapsed)
+	}
+	if got := ev.get(); !slices.Equal(got, []string{"db closed"}) {
+		t.Errorf("events = %v, want the database closed", got)
+	}
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const startKey = "metrics:start"
+
+var (
+	dbQueries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "queries_total",
+		Help:      "GORM 执行的 SQL 语句数，按操作、表和结果统计",
+	}, []string{"operation", "table", "result"})
+
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "db",
+		Name:      "query_duration_seconds",
+		Help:      "GORM 执行 SQL 语句的耗时（秒）",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14), // 0.5ms ~ 4s
+	}, []string{"operation", "table"})
+)
+
+// InstrumentDB 为 db 注册 GORM 回调记录每条语句的次数和耗时，
+// 并以 go_sql_* 指标导出连接池状态，name 作为其 db_name 标签
+func InstrumentDB(db *gorm.DB, name string) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return fmt.Errorf("get generic database object: %w", err)
+	}
+	if err := Registry.Register(collectors.NewDBStatsCollector(sqlDB, name)); err != nil {
+		return fmt.Errorf("register db stats collector: %w", err)
+	}
+
+	// 计时只包含执行 SQL 的内置回调，不包含事务和关联保存
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("metrics:before_create", startTimer),
+		cb.Create().After("gorm:create").Register("metrics:after_create", observeQuery("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", startTimer),
+		cb.Query().After("gorm:query").Register("metrics:after_query", observeQuery("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", startTimer),
+		cb.Update().After("gorm:update").Register("metrics:after_update", observeQuery("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", startTimer),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", observeQuery("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", startTimer),
+		cb.Row().After("gorm:row").Register("metrics:after_row", observeQuery("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", startTimer),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", observeQuery("raw")),
+	)
+}
+
+func startTimer(db *gorm.DB) {
+	db.InstanceSet(startKey, time.Now())
+}
+
+func observeQuery(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		v, ok := db.InstanceGet(startKey)
+		if !ok {
+			return
+		}
+		start, _ := v.(time.Time)
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		// 查询不到记录不算失败
+		result := "ok"
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			result = "error"
+		}
+
+		dbQueries.WithLabelValues(operation, table, result).Inc()
+		dbQueryDuration.WithLabelValues(operation, table).Observe(time.Since(start).Seconds())
+	}
+}
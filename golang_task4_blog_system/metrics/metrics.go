@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "blog"
+
+// Registry 应用指标注册表，除业务指标外还包含 Go 运行时和进程指标
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP 请求数，按方法、路由和状态码统计",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP 请求处理耗时（秒）",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	httpInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "正在处理的 HTTP 请求数",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequests,
+		httpDuration,
+		httpInFlight,
+		dbQueries,
+		dbQueryDuration,
+	)
+}
+
+// Handler 以 Prometheus 文本格式输出 Registry 中的指标
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
+
+// RequestStarted 记录开始处理一个请求，返回的函数在请求结束时调用
+func RequestStarted() func(method, route string, status int) {
+	start := time.Now()
+	httpInFlight.Inc()
+	return func(method, route string, status int) {
+		httpInFlight.Dec()
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+	}
+}
@@ -1,43 +1,91 @@
 package middleware
 
 import (
-	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/logging"
 	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Auth() gin.HandlerFunc {
-	return gin.BasicAuth(gin.Accounts{
-		// 这里留空，我们会动态验证
-	})
+// contextUserKey 认证中间件加载的用户对象在上下文中的键
+const contextUserKey = "currentUser"
+
+// Auth 受保护路由统一使用的认证中间件：
+// 支持 Authorization: Bearer <token> 与 Authorization: Basic 两种方式，
+// 认证通过后都会把用户写入上下文
+func Auth(users service.UserService) gin.HandlerFunc {
+	basic, bearer := BasicAuth(users), JWTAuth(users)
+	return func(c *gin.Context) {
+		scheme, _, _ := strings.Cut(c.GetHeader("Authorization"), " ")
+		if strings.EqualFold(scheme, "Basic") {
+			basic(c)
+			return
+		}
+		bearer(c)
+	}
 }
 
-func BasicAuth() gin.HandlerFunc {
-	// 从数据库加载所有用户到内存
-	// accounts := gin.Accounts{}
-	// var user models.User
-	// database.DB.Find(&user)
-	// accounts[user.Username] = user.Password
-	return gin.BasicAuth(gin.Accounts{ //临时测试
-		"user_name":"wilson",
-		"Password":"wilson1234",
-	})
+// OptionalAuth 用于公开路由：未携带 Authorization 时以匿名身份继续，
+// 携带时按 Auth 校验，便于作者在公开接口中看到自己的草稿
+func OptionalAuth(users service.UserService) gin.HandlerFunc {
+	auth := Auth(users)
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.Next()
+			return
+		}
+		auth(c)
+	}
 }
 
-// GetCurrentUser 获取当前登录用户
-func GetCurrentUser(c *gin.Context) *models.User {
-	username, exists := c.Get(gin.AuthUserKey)
-	if !exists {
-		return nil
+// BasicAuth 通过用户名和密码进行 HTTP Basic 认证，与登录接口共用失败计数和账户锁定
+func BasicAuth(users service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		username, password, ok := c.Request.BasicAuth()
+		if !ok || username == "" {
+			abortBasicUnauthorized(c, apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证"))
+			return
+		}
+
+		user, err := users.Authenticate(c.Request.Context(), username, password)
+		if err != nil {
+			if apperr.IsKind(err, apperr.KindUnauthorized) {
+				abortBasicUnauthorized(c, apperr.Unauthorized(apperr.CodeInvalidCredentials, "用户名或密码错误"))
+				return
+			}
+			abortWithError(c, err)
+			return
+		}
+
+		c.Set(gin.AuthUserKey, user.Username)
+		SetCurrentUser(c, user)
+		logging.SetUserID(c.Request.Context(), user.ID)
+		c.Next()
 	}
-	
-	var user models.User
-	if err := database.DB.Where("username = ?", username.(string)).First(&user).Error; err != nil {
-		return nil
+}
+
+func abortBasicUnauthorized(c *gin.Context, err *apperr.Error) {
+	c.Header("WWW-Authenticate", `Basic realm="Authorization Required", charset="UTF-8"`)
+	abortWithError(c, err)
+}
+
+// GetCurrentUser 获取认证中间件加载的当前用户，未认证时返回 nil
+func GetCurrentUser(c *gin.Context) *models.User {
+	if cached, ok := c.Get(contextUserKey); ok {
+		if user, ok := cached.(*models.User); ok {
+			return user
+		}
 	}
-	
-	return &user
+	return nil
+}
+
+// SetCurrentUser 把已认证的用户写入上下文，之后 GetCurrentUser 直接返回该用户
+func SetCurrentUser(c *gin.Context, user *models.User) {
+	c.Set(ContextUserIDKey, user.ID)
+	c.Set(contextUserKey, user)
 }
 
 // GetCurrentUserID 获取当前用户ID
@@ -48,18 +96,3 @@ func GetCurrentUserID(c *gin.Context) uint {
 	}
 	return user.ID
 }
-
-// IsPostAuthor 检查当前用户是否是文章作者
-func IsPostAuthor(c *gin.Context, postID uint) bool {
-	currentUserID := GetCurrentUserID(c)
-	if currentUserID == 0 {
-		return false
-	}
-	
-	var post models.Post
-	if err := database.DB.First(&post, postID).Error; err != nil {
-		return false
-	}
-	
-	return post.UserID == currentUserID
-}
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/logging"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors 统一输出错误响应。处理函数和中间件通过 c.Error 记录错误后直接返回，
+// 由这里按最后一个错误渲染：
+//
+//	{"code": "validation_failed", "error": "输入验证失败", "message": "...",
+//	 "fields": [{"field": "email", "code": "email", "message": "..."}], "request_id": "..."}
+//
+// code 为稳定的错误码，message 和 fields 只在有补充信息时返回；
+// 429 响应带 Retry-After 头，retry_after 为需等待的秒数；
+// 非 apperr.Error 的错误按 500 处理，原始错误只出现在请求日志中
+func Errors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		e := apperr.As(c.Errors.Last().Err)
+		if e.RetryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(retryAfterSeconds(e.RetryAfter)))
+		}
+		c.JSON(e.Status(), errorBody(c, e))
+	}
+}
+
+// NotFound 未匹配任何路由时返回统一格式的 404
+func NotFound(c *gin.Context) {
+	c.Error(apperr.NotFound(apperr.CodeRouteNotFound, "接口不存在"))
+}
+
+func errorBody(c *gin.Context, e *apperr.Error) gin.H {
+	body := gin.H{}
+	for k, v := range e.Meta {
+		body[k] = v
+	}
+	body["code"] = e.Code
+	body["error"] = e.Message
+	if e.Detail != "" {
+		body["message"] = e.Detail
+	}
+	if len(e.Fields) > 0 {
+		body["fields"] = e.Fields
+	}
+	if e.RetryAfter > 0 {
+		body["retry_after"] = retryAfterSeconds(e.RetryAfter)
+	}
+	if id := logging.RequestID(c.Request.Context()); id != "" {
+		body["request_id"] = id
+	}
+	return body
+}
+
+// retryAfterSeconds Retry-After 以整秒表示，向上取整
+func retryAfterSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// abortWithError 记录错误并终止后续处理函数，响应由 Errors 输出
+func abortWithError(c *gin.Context, err error) {
+	c.Error(err)
+	c.Abort()
+}
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantRetry  string
+		want       map[string]any // 响应体中应有的键值，nil 值表示键不应出现
+	}{
+		{
+			name:       "validation with fields",
+			err:        apperr.InvalidField("email", "email", "email 不是有效的邮箱地址"),
+			wantStatus: http.StatusBadRequest,
+			want: map[string]any{
+				"code":       apperr.CodeValidationFailed,
+				"error":      "输入验证失败",
+				"message":    "email 不是有效的邮箱地址",
+				"fields":     []any{map[string]any{"field": "email", "code": "email", "message": "email 不是有效的邮箱地址"}},
+				"request_id": "req-1",
+			},
+		},
+		{
+			name:       "not found without detail",
+			err:        apperr.NotFound(apperr.CodePostNotFound, "文章不存在"),
+			wantStatus: http.StatusNotFound,
+			want:       map[string]any{"code": apperr.CodePostNotFound, "message": nil, "fields": nil, "retry_after": nil},
+		},
+		{
+			name:       "retry after rounds up",
+			err:        apperr.TooManyRequests(apperr.CodeRateLimited, "请求过于频繁", 1500*time.Millisecond),
+			wantStatus: http.StatusTooManyRequests,
+			wantRetry:  "2",
+			want:       map[string]any{"code": apperr.CodeRateLimited, "retry_after": float64(2)},
+		},
+		{
+			name:       "retry after whole seconds",
+			err:        apperr.TooManyRequests(apperr.CodeAccountLocked, "账户已锁定", 3*time.Second),
+			wantStatus: http.StatusTooManyRequests,
+			wantRetry:  "3",
+			want:       map[string]any{"retry_after": float64(3)},
+		},
+		{
+			name:       "meta at top level",
+			err:        apperr.NotFound(apperr.CodeCommentNotFound, "评论不存在").WithMeta("comment_ids", []uint{7}),
+			wantStatus: http.StatusNotFound,
+			want:       map[string]any{"code": apperr.CodeCommentNotFound, "comment_ids": []any{float64(7)}},
+		},
+		{
+			name:       "wrapped apperr",
+			err:        errors.Join(errors.New("context"), apperr.Forbidden(apperr.CodeForbidden, "权限不足")),
+			wantStatus: http.StatusForbidden,
+			want:       map[string]any{"code": apperr.CodeForbidden},
+		},
+		{
+			name:       "plain error is internal",
+			err:        errors.New("dial tcp: connection refused"),
+			wantStatus: http.StatusInternalServerError,
+			want:       map[string]any{"code": apperr.CodeInternal, "error": "服务器内部错误", "message": nil},
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), "req-1"))
+			}, Errors())
+			router.GET("/", func(c *gin.Context) {
+				c.Error(errors.New("earlier error"))
+				c.Error(tt.err)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+
+			var body map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range tt.want {
+				got, ok := body[key]
+				if want == nil {
+					if ok {
+						t.Errorf("%s = %v, want absent", key, got)
+					}
+					continue
+				}
+				gotJSON, _ := json.Marshal(got)
+				wantJSON, _ := json.Marshal(want)
+				if string(gotJSON) != string(wantJSON) {
+					t.Errorf("%s = %s, want %s", key, gotJSON, wantJSON)
+				}
+			}
+		})
+	}
+}
+
+// TestErrorsWrittenResponse 处理函数已写出响应时不再输出错误
+func TestErrorsWrittenResponse(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Errors())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "partial")
+		c.Error(errors.New("after write"))
+	})
+	router.NoRoute(NotFound)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "partial" {
+		t.Errorf("response = %d %q, want untouched", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	var body struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusNotFound || body.Code != apperr.CodeRouteNotFound {
+		t.Errorf("unmatched route = %d %s, want 404 %s", w.Code, body.Code, apperr.CodeRouteNotFound)
+	}
+}
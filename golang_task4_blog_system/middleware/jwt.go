@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/logging"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ContextUserIDKey 认证通过后当前用户ID在 gin.Context 中的键
+const ContextUserIDKey = "userID"
+
+// AccessTokenTTL 访问令牌有效期，过期后使用刷新令牌换取新令牌
+var AccessTokenTTL = 15 * time.Minute
+
+var jwtSecret []byte
+
+// SetJWTSecret 设置签名JWT令牌的密钥，需在启动时调用
+func SetJWTSecret(secret string) {
+	jwtSecret = []byte(secret)
+}
+
+// Claims JWT令牌中携带的用户信息
+type Claims struct {
+	UserID   uint   `json:"uid"`
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// GenerateToken 为用户签发访问令牌，返回令牌及其过期时间
+func GenerateToken(user *models.User) (string, time.Time, error) {
+	if len(jwtSecret) == 0 {
+		return "", time.Time{}, errors.New("jwt secret not configured")
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(AccessTokenTTL)
+	claims := Claims{
+		UserID:   user.ID,
+		Username: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// ParseToken 校验并解析访问令牌
+func ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(t *jwt.Token) (interface{}, error) {
+		if t.Method != jwt.SigningMethodHS256 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return jwtSecret, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid || claims.UserID == 0 {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// JWTAuth 校验 Authorization: Bearer <token>，并将用户写入上下文
+func JWTAuth(users service.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		scheme, tokenString, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || tokenString == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			abortWithError(c, apperr.Unauthorized(apperr.CodeTokenMissing, "缺少访问令牌"))
+			return
+		}
+
+		claims, err := ParseToken(strings.TrimSpace(tokenString))
+		if err != nil {
+			abortInvalidToken(c)
+			return
+		}
+
+		// 用户不存在或已注销时，未过期的令牌同样失效
+		user, err := users.GetByID(c.Request.Context(), claims.UserID)
+		if err != nil && !apperr.IsKind(err, apperr.KindNotFound) {
+			abortWithError(c, err)
+			return
+		}
+		if err != nil || user.IsDeleted() {
+			abortInvalidToken(c)
+			return
+		}
+
+		SetCurrentUser(c, user)
+		logging.SetUserID(c.Request.Context(), user.ID)
+		c.Next()
+	}
+}
+
+func abortInvalidToken(c *gin.Context) {
+	c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+	abortWithError(c, apperr.Unauthorized(apperr.CodeTokenInvalid, "访问令牌无效或已过期"))
+}
@@ -1,20 +1,79 @@
 package middleware
 
 import (
-	"log"
+	"crypto/rand"
+	"encoding/hex"
+	"golang_task4_blog_system/logging"
+	"log/slog"
+	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// RequestIDHeader 请求ID的请求头和响应头
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLen = 128
+
+// Logger 为每个请求分配请求ID并在请求结束时输出一条结构化日志。
+// 请求头中带有合法的 X-Request-ID 时沿用，否则生成新的ID，并在响应头中返回；
+// 请求ID写入 c.Request 的上下文，使用该上下文的日志（包括 SQL 日志）都会带上请求ID和用户ID
 func Logger() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now()
 
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		c.Request = c.Request.WithContext(ctx)
+
 		c.Next()
 
-		duration := time.Since(start)
-		log.Printf("Method: %s | Path: %s | Status: %d | Duration: %v",
-			c.Request.Method, c.Request.URL.Path, c.Writer.Status(), duration)
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", status),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+		slog.LogAttrs(ctx, level, "request", attrs...)
 	}
-}
\ No newline at end of file
+}
+
+// validRequestID 只接受长度有限的可打印 ASCII 字符，防止日志注入
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// captureLogs 把 slog 默认日志改为写入缓冲区的 debug 级 JSON 日志，测试结束时恢复
+func captureLogs(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	prev, prevOutput, prevFlags := slog.Default(), log.Writer(), log.Flags()
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+		log.SetOutput(prevOutput)
+		log.SetFlags(prevFlags)
+	})
+	var buf bytes.Buffer
+	if err := logging.Setup(&buf, logging.FormatJSON, "debug"); err != nil {
+		t.Fatal(err)
+	}
+	return &buf
+}
+
+// TestLoggerRequestID 合法的 X-Request-ID 原样沿用，缺失或不合法时重新生成；
+// 请求日志和使用请求上下文的 SQL 日志都带有请求ID
+func TestLoggerRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	buf := captureLogs(t)
+
+	// SQL 日志在打开连接时绑定 slog 默认日志，须在 captureLogs 之后打开
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	router := gin.New()
+	router.Use(Logger())
+	router.GET("/query", func(c *gin.Context) {
+		var n int
+		db.WithContext(c.Request.Context()).Raw("SELECT 42").Scan(&n)
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name   string
+		header string
+		keep   bool // 响应是否沿用请求头中的ID
+	}{
+		{"valid id kept", "client-id-123", true},
+		{"missing id generated", "", false},
+		{"id with spaces regenerated", "bad id", false},
+		{"id with newline regenerated", "bad\nid", false},
+		{"overlong id regenerated", strings.Repeat("a", maxRequestIDLen+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/query", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if tt.keep && id != tt.header {
+				t.Errorf("request id = %q, want %q", id, tt.header)
+			}
+			if !tt.keep && (id == tt.header || !validRequestID(id) || len(id) != 32) {
+				t.Errorf("request id = %q, want a new 32-character id", id)
+			}
+
+			// 逐行检查 JSON 日志：SQL 日志和请求日志都要带上同一个请求ID
+			var sawSQL, sawRequest bool
+			scanner := bufio.NewScanner(buf)
+			for scanner.Scan() {
+				var entry map[string]any
+				if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+					t.Fatalf("invalid log line %q", scanner.Text())
+				}
+				line := scanner.Text()
+				switch {
+				case entry["msg"] == "request":
+					sawRequest = true
+				case strings.Contains(line, "SELECT 42"):
+					sawSQL = true
+				default:
+					continue
+				}
+				if entry["request_id"] != id {
+					t.Errorf("log line request_id = %v, want %s: %s", entry["request_id"], id, line)
+				}
+			}
+			if !sawSQL || !sawRequest {
+				t.Errorf("missing logs (sql %v, request %v):\n%s", sawSQL, sawRequest, buf.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"golang_task4_blog_system/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics 记录每个请求的次数、耗时和状态码。
+// 按路由模板（如 /api/posts/:id）而不是实际路径统计，未匹配路由的请求归入 "unmatched"，避免标签数量无限增长
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		done := metrics.RequestStarted()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(c.Request.Method, route, c.Writer.Status())
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang_task4_blog_system/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestMetricsRouteLabels 按路由模板统计请求，未匹配路由的请求归入 unmatched
+func TestMetricsRouteLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics(), Errors())
+	router.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.NoRoute(NotFound)
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing/3"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	exposition := string(body)
+
+	for _, want := range []string{
+		`blog_http_requests_total{method="GET",route="/metrics-test/:id",status="204"} 2`,
+		`blog_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`blog_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id"} 2`,
+	} {
+		if !strings.Contains(exposition, want) {
+			t.Errorf("metrics missing %s", want)
+		}
+	}
+	for _, path := range []string{"/metrics-test/1", "/metrics-test-missing/3"} {
+		if strings.Contains(exposition, `route="`+path+`"`) {
+			t.Errorf("metrics labelled with raw path %s", path)
+		}
+	}
+}
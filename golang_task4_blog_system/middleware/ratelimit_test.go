@@ -0,0 +1,83 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newLimitedRouter 每个键只允许一次请求，响应体为限流使用的键
+func newLimitedRouter(t *testing.T, trustedProxies []string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		t.Fatal(err)
+	}
+	router.Use(Errors())
+	limit := ratelimit.Every(1, time.Hour, 1)
+	router.GET("/limited", RateLimit(ratelimit.NewMemoryStore(), "test", limit, ByIP), func(c *gin.Context) {
+		c.String(http.StatusOK, ByIP(c))
+	})
+	return router
+}
+
+func requestFrom(router http.Handler, remoteAddr, forwardedFor string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/limited", nil)
+	req.RemoteAddr = remoteAddr
+	if forwardedFor != "" {
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		req.Header.Set("X-Real-IP", forwardedFor)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestByIPIgnoresForgedForwardedFor(t *testing.T) {
+	router := newLimitedRouter(t, nil)
+
+	first := requestFrom(router, "203.0.113.7:40000", "198.51.100.1")
+	if first.Code != http.StatusOK || first.Body.String() != "ip:203.0.113.7" {
+		t.Fatalf("first request: %d %q, want key of the connection address", first.Code, first.Body.String())
+	}
+
+	// 每次换一个伪造的 X-Forwarded-For 也不能得到新的令牌桶
+	for _, forged := range []string{"198.51.100.2", "198.51.100.3, 10.0.0.1", ""} {
+		if w := requestFrom(router, "203.0.113.7:40001", forged); w.Code != http.StatusTooManyRequests {
+			t.Errorf("request with X-Forwarded-For %q: %d, want 429", forged, w.Code)
+		}
+	}
+}
+
+func TestByIPUsesForwardedForFromTrustedProxy(t *testing.T) {
+	router := newLimitedRouter(t, []string{"10.0.0.0/8"})
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		wantCode   int
+		wantKey    string
+	}{
+		{"client behind proxy", "10.0.0.5:5000", "198.51.100.1", http.StatusOK, "ip:198.51.100.1"},
+		{"another client behind proxy", "10.0.0.5:5001", "198.51.100.2", http.StatusOK, "ip:198.51.100.2"},
+		{"same client again", "10.0.0.6:5002", "198.51.100.1", http.StatusTooManyRequests, ""},
+		{"untrusted peer forging header", "203.0.113.7:40000", "198.51.100.3", http.StatusOK, "ip:203.0.113.7"},
+		{"untrusted peer second forgery", "203.0.113.7:40001", "198.51.100.4", http.StatusTooManyRequests, ""},
+	}
+	for _, tt := range tests {
+		w := requestFrom(router, tt.remoteAddr, tt.forwarded)
+		if w.Code != tt.wantCode {
+			t.Fatalf("%s: status %d, want %d", tt.name, w.Code, tt.wantCode)
+		}
+		if tt.wantKey != "" && w.Body.String() != tt.wantKey {
+			t.Errorf("%s: key %q, want %q", tt.name, w.Body.String(), tt.wantKey)
+		}
+	}
+}
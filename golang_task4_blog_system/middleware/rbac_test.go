@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequirePermission(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string // 为空表示未认证
+		verified   bool
+		perms      []string
+		wantStatus int
+		wantCode   string
+	}{
+		{"anonymous", "", false, []string{models.PermCommentsCreate}, http.StatusUnauthorized, apperr.CodeUnauthorized},
+		{"unverified admin", models.RoleAdmin, false, []string{models.PermCommentsCreate}, http.StatusForbidden, apperr.CodeEmailNotVerified},
+		{"reader creates comment", models.RoleReader, true, []string{models.PermCommentsCreate}, http.StatusOK, ""},
+		{"reader creates post", models.RoleReader, true, []string{models.PermPostsCreate}, http.StatusForbidden, apperr.CodeForbidden},
+		{"author updates own post", models.RoleAuthor, true, []string{models.PermPostsUpdateOwn, models.PermPostsUpdateAny}, http.StatusOK, ""},
+		{"author manages categories", models.RoleAuthor, true, []string{models.PermCategoriesManage}, http.StatusForbidden, apperr.CodeForbidden},
+		{"author moderates own", models.RoleAuthor, true, []string{models.PermCommentsModerateOwn, models.PermCommentsModerateAny}, http.StatusOK, ""},
+		{"editor manages categories", models.RoleEditor, true, []string{models.PermCategoriesManage}, http.StatusOK, ""},
+		{"editor manages users", models.RoleEditor, true, []string{models.PermUsersManage}, http.StatusForbidden, apperr.CodeForbidden},
+		{"editor updates any post", models.RoleEditor, true, []string{models.PermPostsUpdateAny}, http.StatusForbidden, apperr.CodeForbidden},
+		{"admin manages users", models.RoleAdmin, true, []string{models.PermUsersManage}, http.StatusOK, ""},
+		{"unknown role", "guest", true, []string{models.PermCommentsCreate}, http.StatusForbidden, apperr.CodeForbidden},
+	}
+	gin.SetMode(gin.TestMode)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(Errors())
+			router.GET("/protected", func(c *gin.Context) {
+				if tt.role != "" {
+					user := &models.User{ID: 1, Role: tt.role}
+					if tt.verified {
+						now := time.Now()
+						user.EmailVerifiedAt = &now
+					}
+					SetCurrentUser(c, user)
+				}
+			}, RequirePermission(tt.perms...), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/protected", nil))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d %s, want %d", w.Code, w.Body.String(), tt.wantStatus)
+			}
+			if tt.wantCode == "" {
+				return
+			}
+			var resp struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			if resp.Code != tt.wantCode {
+				t.Errorf("code = %q, want %q", resp.Code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/migrations"
+	"os"
+	"strconv"
+	"text/tabwriter"
+)
+
+const migrateUsage = `usage: migrate <command>
+
+commands:
+  up            apply all pending migrations
+  down [n]      roll back the last n applied migrations (default 1)
+  to <version>  migrate up or down to the given version (0 rolls back everything)
+  status        list migrations and whether they are applied`
+
+// runMigrate 执行 migrate 子命令
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	switch args[0] {
+	case "up":
+		return migrations.Up(database.DB)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+			steps = n
+		}
+		return migrations.Down(database.DB, steps)
+	case "to":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		version, err := strconv.ParseUint(args[1], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid version %q", args[1])
+		}
+		return migrations.To(database.DB, uint(version))
+	case "status":
+		return printMigrationStatus()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
+
+func printMigrationStatus() error {
+	statuses, err := migrations.GetStatus(database.DB)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return w.Flush()
+}
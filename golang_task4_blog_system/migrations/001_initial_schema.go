@@ -0,0 +1,197 @@
+package migrations
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// initialSchema 创建用户、文章、评论、刷新令牌、文章历史 slug、标签和分类的表，以及它们的索引和外键。
+//
+// 引入版本迁移之前的数据库里可能已有 users、posts、comments 三张表（只有最初的字段），
+// 这里会补齐缺少的字段并为已有数据填充默认值：文章 slug 设为 post-<ID>，
+// 已发布文章的发布时间取创建时间，已有评论视为审核通过。
+//
+// 下面的结构体是该版本的表结构快照，之后修改 models 时不要修改这里，而是新增版本。
+var initialSchema = Migration{
+	Version: 1,
+	Name:    "initial_schema",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		legacyPosts := m.HasTable("posts") && !m.HasColumn("posts", "slug")
+		legacyComments := m.HasTable("comments") && !m.HasColumn("comments", "status")
+
+		// 旧数据的 slug 需要在创建唯一索引之前填充
+		if legacyPosts {
+			if err := m.AddColumn(&v1LegacyPost{}, "Slug"); err != nil {
+				return err
+			}
+			var ids []uint
+			if err := tx.Table("posts").Pluck("id", &ids).Error; err != nil {
+				return err
+			}
+			for _, id := range ids {
+				if err := tx.Table("posts").Where("id = ?", id).
+					Update("slug", fmt.Sprintf("post-%d", id)).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := m.AutoMigrate(
+			&v1User{},
+			&v1Category{},
+			&v1Tag{},
+			&v1Post{},
+			&v1Comment{},
+			&v1RefreshToken{},
+			&v1PostTag{},
+			&v1PostSlug{},
+		); err != nil {
+			return err
+		}
+
+		if legacyPosts {
+			if err := tx.Table("posts").
+				Where("status = ? AND published_at IS NULL", "published").
+				Update("published_at", gorm.Expr("created_at")).Error; err != nil {
+				return err
+			}
+		}
+		if legacyComments {
+			if err := tx.Table("comments").Where("1 = 1").
+				Update("status", "approved").Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		// DropTable 按参数的倒序删除，这里按外键依赖顺序列出
+		return tx.Migrator().DropTable(
+			"users",
+			"categories",
+			"tags",
+			"posts",
+			"comments",
+			"refresh_tokens",
+			"post_tags",
+			"post_slugs",
+		)
+	},
+}
+
+type v1User struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement"`
+	Username string `gorm:"size:50;uniqueIndex;not null"`
+	Email    string `gorm:"size:100;uniqueIndex;not null"`
+	Password string `gorm:"size:255;not null"`
+	Role     string `gorm:"size:20;not null;default:author"`
+
+	Posts    []v1Post    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Comments []v1Comment `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v1User) TableName() string { return "users" }
+
+type v1Category struct {
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	Name        string `gorm:"size:100;not null"`
+	Slug        string `gorm:"size:120;not null;uniqueIndex"`
+	Description string `gorm:"size:500"`
+	ParentID    *uint  `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Parent *v1Category `gorm:"foreignKey:ParentID"`
+}
+
+func (v1Category) TableName() string { return "categories" }
+
+type v1Tag struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:50;not null"`
+	Slug      string `gorm:"size:80;not null;uniqueIndex"`
+	CreatedAt time.Time
+}
+
+func (v1Tag) TableName() string { return "tags" }
+
+type v1Post struct {
+	ID          uint       `gorm:"primaryKey;autoIncrement"`
+	Title       string     `gorm:"size:200;not null"`
+	Content     string     `gorm:"type:text;not null"`
+	Slug        string     `gorm:"size:255;not null;uniqueIndex"`
+	Status      string     `gorm:"size:20;not null;default:published;index"`
+	PublishAt   *time.Time `gorm:"index"`
+	PublishedAt *time.Time
+	UserID      uint  `gorm:"not null;index"`
+	CategoryID  *uint `gorm:"index"`
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	Comments []v1Comment `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;"`
+	Category *v1Category `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL;"`
+}
+
+func (v1Post) TableName() string { return "posts" }
+
+// v1PostTag 文章和标签的多对多关联表
+type v1PostTag struct {
+	PostID uint `gorm:"primaryKey"`
+	TagID  uint `gorm:"primaryKey"`
+
+	Post v1Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;"`
+	Tag  v1Tag  `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v1PostTag) TableName() string { return "post_tags" }
+
+// v1LegacyPost 用于给旧的 posts 表添加允许默认值的 slug 字段
+type v1LegacyPost struct {
+	Slug string `gorm:"size:255;not null;default:''"`
+}
+
+func (v1LegacyPost) TableName() string { return "posts" }
+
+type v1Comment struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Content   string `gorm:"type:text;not null"`
+	Status    string `gorm:"size:20;not null;default:pending;index"`
+	UserID    uint   `gorm:"not null;index"`
+	PostID    uint   `gorm:"not null;index"`
+	ParentID  *uint  `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Replies []v1Comment `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v1Comment) TableName() string { return "comments" }
+
+type v1RefreshToken struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	UserID       uint      `gorm:"not null;index"`
+	FamilyID     string    `gorm:"size:64;not null;index"`
+	TokenHash    string    `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt    time.Time `gorm:"not null"`
+	RevokedAt    *time.Time
+	ReplacedByID *uint
+	CreatedAt    time.Time
+
+	User v1User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v1RefreshToken) TableName() string { return "refresh_tokens" }
+
+type v1PostSlug struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	PostID    uint   `gorm:"not null;index"`
+	Slug      string `gorm:"size:255;not null;uniqueIndex"`
+	CreatedAt time.Time
+
+	Post v1Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v1PostSlug) TableName() string { return "post_slugs" }
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// emailVerification 为用户增加邮箱验证时间，并创建邮箱验证和密码重置使用的一次性令牌表。
+// 引入邮箱验证之前注册的用户视为已验证。
+var emailVerification = Migration{
+	Version: 2,
+	Name:    "email_verification",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.AddColumn(&v2User{}, "EmailVerifiedAt"); err != nil {
+			return err
+		}
+		if err := tx.Table("users").Where("email_verified_at IS NULL").
+			Update("email_verified_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return m.AutoMigrate(&v2UserToken{})
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		if err := m.DropTable("user_tokens"); err != nil {
+			return err
+		}
+		return m.DropColumn(&v2User{}, "EmailVerifiedAt")
+	},
+}
+
+// v2User 本版本为 users 表新增的字段
+type v2User struct {
+	EmailVerifiedAt *time.Time
+}
+
+func (v2User) TableName() string { return "users" }
+
+type v2UserToken struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	UserID    uint      `gorm:"not null;index"`
+	Purpose   string    `gorm:"size:32;not null"`
+	TokenHash string    `gorm:"size:64;uniqueIndex;not null"`
+	Email     string    `gorm:"size:100;not null"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+
+	User v1User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (v2UserToken) TableName() string { return "user_tokens" }
@@ -0,0 +1,38 @@
+package migrations
+
+import "gorm.io/gorm"
+
+// userProfile 为用户增加显示名称、简介和头像地址
+var userProfile = Migration{
+	Version: 3,
+	Name:    "user_profile",
+	Up: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range v3UserFields {
+			if err := m.AddColumn(&v3User{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Down: func(tx *gorm.DB) error {
+		m := tx.Migrator()
+		for _, field := range v3UserFields {
+			if err := m.DropColumn(&v3User{}, field); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+}
+
+// v3User 本版本为 users 表新增的字段
+type v3User struct {
+	DisplayName string `gorm:"size:50"`
+	Bio         string `gorm:"size:500"`
+	AvatarURL   string `gorm:"size:255"`
+}
+
+func (v3User) TableName() string { return "users" }
+
+var v3UserFields = []string{"DisplayName", "Bio", "AvatarURL"}
@@ -0,0 +1,26 @@
+package migrations
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// accountDeletion 为用户增加注销时间。注销的用户保留匿名化的记录，使其评论不随账户一起删除
+var accountDeletion = Migration{
+	Version: 4,
+	Name:    "account_deletion",
+	Up: func(tx *gorm.DB) error {
+		return tx.Migrator().AddColumn(&v4User{}, "DeletedAt")
+	},
+	Down: func(tx *gorm.DB) error {
+		return tx.Migrator().DropColumn(&v4User{}, "DeletedAt")
+	},
+}
+
+// v4User 本版本为 users 表新增的字段
+type v4User struct {
+	DeletedAt *time.Time
+}
+
+func (v4User) TableName() string { return "users" }
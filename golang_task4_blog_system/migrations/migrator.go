@@ -0,0 +1,249 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration 一个版本的数据库结构变更，Up 升级，Down 回滚；
+// 每个版本在独立事务中执行（MySQL 的 DDL 会隐式提交，失败时需要手动检查）
+type Migration struct {
+	Version uint
+	Name    string
+	Up      func(tx *gorm.DB) error
+	Down    func(tx *gorm.DB) error
+}
+
+// schemaMigration 已执行的版本记录
+type schemaMigration struct {
+	Version   uint      `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"size:255;not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Status 版本的执行状态，AppliedAt 为空表示尚未执行
+type Status struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+}
+
+var errNoDown = errors.New("migration cannot be rolled back")
+
+// all 全部版本，按版本号递增排列；新增版本追加到末尾，已发布的版本不要修改
+var all = []Migration{
+	initialSchema,
+	emailVerification,
+	userProfile,
+	accountDeletion,
+}
+
+// Latest 最新版本号
+func Latest() uint {
+	return all[len(all)-1].Version
+}
+
+// Up 执行全部未执行的版本
+func Up(db *gorm.DB) error {
+	return To(db, Latest())
+}
+
+// Down 按从新到旧的顺序回滚最近执行的 steps 个版本
+func Down(db *gorm.DB, steps int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	for i := len(all) - 1; i >= 0 && steps > 0; i-- {
+		if _, ok := applied[all[i].Version]; !ok {
+			continue
+		}
+		if err := run(db, all[i], false); err != nil {
+			return err
+		}
+		steps--
+	}
+	return nil
+}
+
+// To 升级或回滚到指定版本，0 表示回滚全部版本
+func To(db *gorm.DB, version uint) error {
+	if version != 0 && find(version) == nil {
+		return fmt.Errorf("unknown migration version %d", version)
+	}
+
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+
+	// 先回滚高于目标的版本（从新到旧），再执行不高于目标的版本（从旧到新）
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; ok && m.Version > version {
+			if err := run(db, m, false); err != nil {
+				return err
+			}
+		}
+	}
+	for _, m := range all {
+		if _, ok := applied[m.Version]; !ok && m.Version <= version {
+			if err := run(db, m, true); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Pending 返回尚未执行的版本
+func Pending(db *gorm.DB) ([]Migration, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, m := range all {
+		if _, ok := applied[m.Version]; !ok {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// GetStatus 返回所有版本的执行状态
+func GetStatus(db *gorm.DB) ([]Status, error) {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(all))
+	for _, m := range all {
+		s := Status{Version: m.Version, Name: m.Name}
+		if record, ok := applied[m.Version]; ok {
+			s.AppliedAt = &record.AppliedAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, nil
+}
+
+func run(db *gorm.DB, m Migration, up bool) error {
+	direction := "down"
+	if up {
+		direction = "up"
+	}
+	log.Printf("Migrating %s: %d_%s", direction, m.Version, m.Name)
+
+	if !up && m.Down == nil {
+		return fmt.Errorf("migration %d_%s: %w", m.Version, m.Name, errNoDown)
+	}
+
+	err := db.Connection(func(conn *gorm.DB) error {
+		conn = conn.Session(&gorm.Session{})
+		return withoutSQLiteForeignKeys(conn, func() error {
+			return conn.Transaction(func(tx *gorm.DB) error {
+				if !up {
+					if err := m.Down(tx); err != nil {
+						return err
+					}
+					return tx.Delete(&schemaMigration{}, m.Version).Error
+				}
+				if err := m.Up(tx); err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   m.Version,
+					Name:      m.Name,
+					AppliedAt: time.Now(),
+				}).Error
+			})
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("migration %d_%s %s: %w", m.Version, m.Name, direction, err)
+	}
+	return nil
+}
+
+// withoutSQLiteForeignKeys 在 SQLite 上关闭外键约束执行 fn。
+// SQLite 修改约束时需要重建表，开启外键时删除旧表会级联删除其他表的数据；
+// 这个设置在事务中无效，conn 必须是固定的单个连接
+func withoutSQLiteForeignKeys(conn *gorm.DB, fn func() error) error {
+	if conn.Dialector.Name() != "sqlite" {
+		return fn()
+	}
+
+	var enabled bool
+	if err := conn.Raw("PRAGMA foreign_keys").Scan(&enabled).Error; err != nil {
+		return err
+	}
+	if !enabled {
+		return fn()
+	}
+
+	if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		return err
+	}
+	defer conn.Exec("PRAGMA foreign_keys = ON")
+
+	if err := fn(); err != nil {
+		return err
+	}
+
+	// 重新开启前检查迁移后的数据是否满足外键约束
+	rows, err := conn.Raw("PRAGMA foreign_key_check").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key check failed on table %s after migration", table)
+	}
+	return rows.Err()
+}
+
+// appliedVersions 读取已执行的版本，数据库中存在代码里没有的版本时报错
+func appliedVersions(db *gorm.DB) (map[uint]schemaMigration, error) {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+
+	var records []schemaMigration
+	if err := db.Find(&records).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[uint]schemaMigration, len(records))
+	for _, r := range records {
+		if find(r.Version) == nil {
+			return nil, fmt.Errorf("database has migration %d_%s that is unknown to this build", r.Version, r.Name)
+		}
+		applied[r.Version] = r
+	}
+	return applied, nil
+}
+
+func find(version uint) *Migration {
+	for i := range all {
+		if all[i].Version == version {
+			return &all[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+package migrations
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/database"
+
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestUpDownSQLite(t *testing.T) {
+	db := openSQLite(t)
+
+	if err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	pending, err := Pending(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("%d pending migration(s) after Up", len(pending))
+	}
+	for _, table := range []string{"users", "posts", "comments", "categories", "tags", "post_tags", "post_slugs", "refresh_tokens", "user_tokens"} {
+		if !db.Migrator().HasTable(table) {
+			t.Errorf("table %s missing after Up", table)
+		}
+	}
+	for _, column := range []string{"email_verified_at", "display_name", "deleted_at"} {
+		if !db.Migrator().HasColumn("users", column) {
+			t.Errorf("column users.%s missing after Up", column)
+		}
+	}
+
+	// 逐个回滚到空库后再次升级，验证每个版本的 Down 都可执行
+	if err := Down(db, len(all)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	if db.Migrator().HasTable("users") {
+		t.Error("users table still exists after rolling back all migrations")
+	}
+	if err := Up(db); err != nil {
+		t.Fatalf("Up after Down: %v", err)
+	}
+
+	status, err := GetStatus(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status) != len(all) {
+		t.Fatalf("status has %d entries, want %d", len(status), len(all))
+	}
+}
+
+func TestToSQLite(t *testing.T) {
+	db := openSQLite(t)
+
+	if err := To(db, 2); err != nil {
+		t.Fatalf("To(2): %v", err)
+	}
+	if db.Migrator().HasColumn("users", "display_name") {
+		t.Error("users.display_name exists before version 3")
+	}
+	if err := To(db, Latest()); err != nil {
+		t.Fatalf("To(latest): %v", err)
+	}
+	if !db.Migrator().HasColumn("users", "display_name") {
+		t.Error("users.display_name missing at latest version")
+	}
+}
+
+// 引入版本迁移之前由 AutoMigrate 根据最初的模型创建的表结构
+type legacyUser struct {
+	ID       uint   `gorm:"primaryKey;autoIncrement"`
+	Username string `gorm:"size:50;uniqueIndex;not null"`
+	Email    string `gorm:"size:100;uniqueIndex;not null"`
+	Password string `gorm:"size:255;not null"`
+
+	Posts    []legacyPost    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+	Comments []legacyComment `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+}
+
+func (legacyUser) TableName() string { return "users" }
+
+type legacyPost struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Title     string    `gorm:"size:200;not null"`
+	Content   string    `gorm:"type:text;not null"`
+	UserID    uint      `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	Comments []legacyComment `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;"`
+}
+
+func (legacyPost) TableName() string { return "posts" }
+
+type legacyComment struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Content   string    `gorm:"type:text;not null"`
+	UserID    uint      `gorm:"not null;index"`
+	PostID    uint      `gorm:"not null;index"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+func (legacyComment) TableName() string { return "comments" }
+
+// TestUpgradeLegacySchema 从 AutoMigrate 创建的旧表升级：补齐字段并填充默认值，已有数据保留，之后可以完整回滚
+func TestUpgradeLegacySchema(t *testing.T) {
+	db := openSQLite(t)
+	if err := db.AutoMigrate(&legacyUser{}, &legacyPost{}, &legacyComment{}); err != nil {
+		t.Fatal(err)
+	}
+	user := legacyUser{Username: "alice", Email: "alice@example.com", Password: "hash"}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	posts := []legacyPost{{Title: "First", Content: "a", UserID: user.ID}, {Title: "Second", Content: "b", UserID: user.ID}}
+	if err := db.Create(&posts).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&legacyComment{Content: "hi", UserID: user.ID, PostID: posts[0].ID}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Up(db); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+
+	var role string
+	if err := db.Table("users").Where("id = ?", user.ID).Pluck("role", &role).Error; err != nil || role != "author" {
+		t.Errorf("legacy user role = %q (err %v), want author", role, err)
+	}
+
+	var upgraded []struct {
+		ID          uint
+		Slug        string
+		Status      string
+		CreatedAt   time.Time
+		PublishedAt *time.Time
+	}
+	if err := db.Table("posts").Order("id").Find(&upgraded).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(upgraded) != len(posts) {
+		t.Fatalf("%d posts after upgrade, want %d", len(upgraded), len(posts))
+	}
+	for _, p := range upgraded {
+		if want := fmt.Sprintf("post-%d", p.ID); p.Slug != want {
+			t.Errorf("post %d slug = %q, want %q", p.ID, p.Slug, want)
+		}
+		if p.Status != "published" || p.PublishedAt == nil || !p.PublishedAt.Equal(p.CreatedAt) {
+			t.Errorf("post %d status %s published_at %v, want published at %v", p.ID, p.Status, p.PublishedAt, p.CreatedAt)
+		}
+	}
+
+	var statuses []string
+	if err := db.Table("comments").Pluck("status", &statuses).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 || statuses[0] != "approved" {
+		t.Errorf("legacy comment statuses = %v, want [approved]", statuses)
+	}
+
+	// 升级后的 slug 唯一索引生效
+	if err := db.Exec("INSERT INTO posts (title, content, slug, user_id) VALUES (?, ?, ?, ?)",
+		"Dup", "c", upgraded[0].Slug, user.ID).Error; err == nil {
+		t.Error("duplicate slug accepted after upgrade")
+	}
+
+	if err := Down(db, len(all)); err != nil {
+		t.Fatalf("Down: %v", err)
+	}
+	for _, table := range []string{"users", "posts", "comments"} {
+		if db.Migrator().HasTable(table) {
+			t.Errorf("table %s still exists after rolling back all migrations", table)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Category 文章分类，通过 ParentID 组成树形结构
+type Category struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name        string    `gorm:"size:100;not null" json:"name" binding:"required,max=100"`
+	Slug        string    `gorm:"size:120;not null;uniqueIndex" json:"slug" binding:"omitempty,max=120"` // 未指定时由名称生成
+	Description string    `gorm:"size:500" json:"description" binding:"max=500"`
+	ParentID    *uint     `gorm:"index" json:"parent_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	// 关联关系
+	Parent *Category `gorm:"foreignKey:ParentID" json:"parent,omitempty" binding:"-"`
+}
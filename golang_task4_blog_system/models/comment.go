@@ -4,15 +4,27 @@ import (
 	"time"
 )
 
+// 评论审核状态
+const (
+	CommentStatusPending  = "pending"
+	CommentStatusApproved = "approved"
+	CommentStatusRejected = "rejected"
+)
+
 type Comment struct {
 	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
 	Content   string    `gorm:"type:text;not null" json:"content" binding:"required"`
+	Status    string    `gorm:"size:20;not null;default:pending;index" json:"status"` // pending, approved, rejected
 	UserID    uint      `gorm:"not null;index" json:"user_id"`
 	PostID    uint      `gorm:"not null;index" json:"post_id"`
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-	
+
 	// 关联关系
-	User User `gorm:"foreignKey:UserID" json:"user"`
-	Post Post `gorm:"foreignKey:PostID" json:"-"`
-}
\ No newline at end of file
+	User UserProfile `gorm:"foreignKey:UserID" json:"user" binding:"-"` // 作者的公开资料
+	Post Post        `gorm:"foreignKey:PostID" json:"-" binding:"-"`
+
+	// 自引用关系：支持回复评论
+	ParentID *uint     `gorm:"index" json:"parent_id"`
+	Replies  []Comment `gorm:"foreignKey:ParentID;constraint:OnDelete:CASCADE;" json:"replies,omitempty"`
+}
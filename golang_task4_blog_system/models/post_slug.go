@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// PostSlug 文章的历史 slug，修改标题后旧链接通过它重定向到当前 slug
+type PostSlug struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	PostID    uint      `gorm:"not null;index" json:"post_id"`
+	Slug      string    `gorm:"size:255;not null;uniqueIndex" json:"slug"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// 关联关系
+	Post Post `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"-"`
+}
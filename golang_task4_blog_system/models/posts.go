@@ -4,15 +4,35 @@ import (
 	"time"
 )
 
+// 文章状态
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled" // 到达 PublishAt 后由后台任务发布
+	PostStatusPublished = "published"
+	PostStatusArchived  = "archived"
+)
+
 type Post struct {
-	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
-	Title     string    `gorm:"size:200;not null" json:"title" binding:"required"`
-	Content   string    `gorm:"type:text;not null" json:"content" binding:"required"`
-	UserID    uint      `gorm:"not null;index" json:"user_id"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
-	
+	ID          uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Title       string     `gorm:"size:200;not null" json:"title" binding:"required"`
+	Content     string     `gorm:"type:text;not null" json:"content" binding:"required"`
+	Slug        string     `gorm:"size:255;not null;uniqueIndex" json:"slug" binding:"omitempty,max=255"` // 未指定时由标题生成
+	Status      string     `gorm:"size:20;not null;default:published;index" json:"status" binding:"omitempty,oneof=draft scheduled published archived"`
+	PublishAt   *time.Time `gorm:"index" json:"publish_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	CategoryID  *uint      `gorm:"index" json:"category_id"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+
 	// 关联关系
-	User     User      `gorm:"foreignKey:UserID" json:"user"`
-	Comments []Comment `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
+	User     UserProfile `gorm:"foreignKey:UserID" json:"user" binding:"-"` // 作者的公开资料
+	Comments []Comment   `gorm:"foreignKey:PostID;constraint:OnDelete:CASCADE;" json:"comments,omitempty"`
+	Category *Category   `gorm:"foreignKey:CategoryID;constraint:OnDelete:SET NULL;" json:"category,omitempty" binding:"-"`
+	Tags     []Tag       `gorm:"many2many:post_tags;constraint:OnDelete:CASCADE;" json:"tags,omitempty" binding:"-"`
+}
+
+// IsPublished 文章是否已公开发布
+func (p *Post) IsPublished() bool {
+	return p.Status == PostStatusPublished
 }
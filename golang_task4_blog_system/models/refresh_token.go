@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌，数据库中只保存令牌的哈希值。
+// 同一次登录派生出的令牌共享 FamilyID，每次刷新都会轮换出新令牌。
+type RefreshToken struct {
+	ID           uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID       uint       `gorm:"not null;index" json:"user_id"`
+	FamilyID     string     `gorm:"size:64;not null;index" json:"family_id"`
+	TokenHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	ReplacedByID *uint      `json:"replaced_by_id,omitempty"`
+	CreatedAt    time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// 关联关系
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// IsActive 令牌未被吊销、未被轮换且未过期
+func (t *RefreshToken) IsActive(now time.Time) bool {
+	return t.RevokedAt == nil && t.ReplacedByID == nil && now.Before(t.ExpiresAt)
+}
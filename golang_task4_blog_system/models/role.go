@@ -0,0 +1,80 @@
+package models
+
+// 用户角色
+const (
+	RoleAdmin  = "admin"  // 管理员：拥有全部权限
+	RoleEditor = "editor" // 编辑：在作者基础上可审核、删除任意评论，管理分类
+	RoleAuthor = "author" // 作者：可发表和管理自己的文章（注册默认角色）
+	RoleReader = "reader" // 读者：只能发表和管理自己的评论
+)
+
+// 权限，格式为 资源:操作[:范围]，范围 own 表示仅限自己的资源，any 表示任意资源
+const (
+	PermPostsCreate         = "posts:create"
+	PermPostsUpdateOwn      = "posts:update:own"
+	PermPostsUpdateAny      = "posts:update:any"
+	PermPostsDeleteOwn      = "posts:delete:own"
+	PermPostsDeleteAny      = "posts:delete:any"
+	PermCommentsCreate      = "comments:create"
+	PermCommentsUpdateOwn   = "comments:update:own"
+	PermCommentsUpdateAny   = "comments:update:any"
+	PermCommentsDeleteOwn   = "comments:delete:own"
+	PermCommentsDeleteAny   = "comments:delete:any"
+	PermCommentsModerateOwn = "comments:moderate:own" // 审核自己文章下的评论
+	PermCommentsModerateAny = "comments:moderate:any"
+	PermCategoriesManage    = "categories:manage"
+	PermUsersManage         = "users:manage"
+)
+
+var readerPermissions = []string{
+	PermCommentsCreate,
+	PermCommentsUpdateOwn,
+	PermCommentsDeleteOwn,
+}
+
+var authorPermissions = append([]string{
+	PermPostsCreate,
+	PermPostsUpdateOwn,
+	PermPostsDeleteOwn,
+	PermCommentsModerateOwn,
+}, readerPermissions...)
+
+var editorPermissions = append([]string{
+	PermCommentsModerateAny,
+	PermCommentsDeleteAny,
+	PermCategoriesManage,
+}, authorPermissions...)
+
+var adminPermissions = append([]string{
+	PermPostsUpdateAny,
+	PermPostsDeleteAny,
+	PermCommentsUpdateAny,
+	PermUsersManage,
+}, editorPermissions...)
+
+// rolePermissions 角色与权限的对应关系
+var rolePermissions = map[string]map[string]bool{
+	RoleReader: permissionSet(readerPermissions),
+	RoleAuthor: permissionSet(authorPermissions),
+	RoleEditor: permissionSet(editorPermissions),
+	RoleAdmin:  permissionSet(adminPermissions),
+}
+
+func permissionSet(perms []string) map[string]bool {
+	set := make(map[string]bool, len(perms))
+	for _, p := range perms {
+		set[p] = true
+	}
+	return set
+}
+
+// IsValidRole 是否为已定义的角色
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// RoleHasPermission 检查角色是否拥有某项权限
+func RoleHasPermission(role, perm string) bool {
+	return rolePermissions[role][perm]
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// allPermissions 全部已定义的权限
+var allPermissions = []string{
+	PermPostsCreate, PermPostsUpdateOwn, PermPostsUpdateAny, PermPostsDeleteOwn, PermPostsDeleteAny,
+	PermCommentsCreate, PermCommentsUpdateOwn, PermCommentsUpdateAny, PermCommentsDeleteOwn, PermCommentsDeleteAny,
+	PermCommentsModerateOwn, PermCommentsModerateAny, PermCategoriesManage, PermUsersManage,
+}
+
+// TestRolePermissions 逐个角色核对拥有的权限，未列出的权限必须没有
+func TestRolePermissions(t *testing.T) {
+	granted := map[string][]string{
+		RoleReader: {PermCommentsCreate, PermCommentsUpdateOwn, PermCommentsDeleteOwn},
+		RoleAuthor: {
+			PermPostsCreate, PermPostsUpdateOwn, PermPostsDeleteOwn,
+			PermCommentsCreate, PermCommentsUpdateOwn, PermCommentsDeleteOwn, PermCommentsModerateOwn,
+		},
+		RoleEditor: {
+			PermPostsCreate, PermPostsUpdateOwn, PermPostsDeleteOwn,
+			PermCommentsCreate, PermCommentsUpdateOwn, PermCommentsDeleteOwn, PermCommentsDeleteAny,
+			PermCommentsModerateOwn, PermCommentsModerateAny, PermCategoriesManage,
+		},
+		RoleAdmin: allPermissions,
+		"guest":   nil,
+	}
+	for role, perms := range granted {
+		t.Run(role, func(t *testing.T) {
+			if IsValidRole(role) != (role != "guest") {
+				t.Errorf("IsValidRole(%q) = %v", role, IsValidRole(role))
+			}
+			for _, perm := range allPermissions {
+				if got, want := RoleHasPermission(role, perm), slices.Contains(perms, perm); got != want {
+					t.Errorf("RoleHasPermission(%q, %q) = %v, want %v", role, perm, got, want)
+				}
+			}
+		})
+	}
+}
+
+// TestCanAccess own 权限只对自己的资源生效，any 权限对任意资源生效，未验证邮箱时一律拒绝
+func TestCanAccess(t *testing.T) {
+	const ownerID, otherID = 1, 2
+	tests := []struct {
+		role     string
+		verified bool
+		action   string
+		own      bool // 能否操作自己的资源
+		any      bool // 能否操作他人的资源
+	}{
+		{RoleReader, true, "posts:update", false, false},
+		{RoleReader, true, "comments:update", true, false},
+		{RoleReader, true, "comments:moderate", false, false},
+		{RoleAuthor, true, "posts:update", true, false},
+		{RoleAuthor, true, "posts:delete", true, false},
+		{RoleAuthor, true, "comments:moderate", true, false},
+		{RoleEditor, true, "posts:update", true, false},
+		{RoleEditor, true, "comments:delete", true, true},
+		{RoleEditor, true, "comments:moderate", true, true},
+		{RoleEditor, true, "comments:update", true, false},
+		{RoleAdmin, true, "posts:update", true, true},
+		{RoleAdmin, true, "posts:delete", true, true},
+		{RoleAdmin, true, "comments:update", true, true},
+		{RoleAdmin, false, "posts:update", false, false},
+		{RoleAuthor, false, "posts:update", false, false},
+	}
+	for _, tt := range tests {
+		user := &User{ID: ownerID, Role: tt.role}
+		if tt.verified {
+			now := time.Now()
+			user.EmailVerifiedAt = &now
+		}
+		if got := user.CanAccess(ownerID, tt.action); got != tt.own {
+			t.Errorf("%s (verified %v) %s own = %v, want %v", tt.role, tt.verified, tt.action, got, tt.own)
+		}
+		if got := user.CanAccess(otherID, tt.action); got != tt.any {
+			t.Errorf("%s (verified %v) %s any = %v, want %v", tt.role, tt.verified, tt.action, got, tt.any)
+		}
+	}
+}
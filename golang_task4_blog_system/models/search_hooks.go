@@ -0,0 +1,146 @@
+package models
+
+import (
+	"golang_task4_blog_system/search"
+
+	"gorm.io/gorm"
+)
+
+// 搜索索引通过模型钩子与数据库保持同步：只索引已发布的文章和已审核通过的评论。
+// 不经过钩子的批量更新（如 Model(&Post{}).Where(...).Updates）需要自行调用 SyncPostSearch/SyncCommentSearch
+
+// AfterSave 文章创建或更新后同步搜索索引
+func (p *Post) AfterSave(tx *gorm.DB) error {
+	if p.ID != 0 {
+		return SyncPostSearch(tx, p.ID)
+	}
+	return nil
+}
+
+// AfterDelete 文章删除后从搜索索引中移除
+func (p *Post) AfterDelete(tx *gorm.DB) error {
+	search.Default.Remove(search.KindPost, p.ID)
+	return nil
+}
+
+// AfterSave 评论创建或更新后同步搜索索引
+func (cm *Comment) AfterSave(tx *gorm.DB) error {
+	if cm.ID != 0 {
+		return SyncCommentSearch(tx, cm.ID)
+	}
+	return nil
+}
+
+// AfterDelete 评论删除后从搜索索引中移除
+func (cm *Comment) AfterDelete(tx *gorm.DB) error {
+	search.Default.Remove(search.KindComment, cm.ID)
+	return nil
+}
+
+// SyncPostSearch 按数据库中的最新状态更新文章索引
+func SyncPostSearch(db *gorm.DB, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var posts []Post
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Select("id", "title", "content", "status").
+		Where("id IN ?", ids).
+		Find(&posts).Error; err != nil {
+		return err
+	}
+
+	found := make(map[uint]bool, len(posts))
+	for i := range posts {
+		found[posts[i].ID] = true
+		indexPost(&posts[i])
+	}
+	for _, id := range ids {
+		if !found[id] {
+			search.Default.Remove(search.KindPost, id)
+		}
+	}
+	return nil
+}
+
+// SyncCommentSearch 按数据库中的最新状态更新评论索引
+func SyncCommentSearch(db *gorm.DB, ids ...uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var comments []Comment
+	if err := db.Session(&gorm.Session{NewDB: true}).
+		Select("id", "post_id", "content", "status").
+		Where("id IN ?", ids).
+		Find(&comments).Error; err != nil {
+		return err
+	}
+
+	found := make(map[uint]bool, len(comments))
+	for i := range comments {
+		found[comments[i].ID] = true
+		indexComment(&comments[i])
+	}
+	for _, id := range ids {
+		if !found[id] {
+			search.Default.Remove(search.KindComment, id)
+		}
+	}
+	return nil
+}
+
+// RebuildSearchIndex 从数据库重建整个搜索索引，启动时调用
+func RebuildSearchIndex(db *gorm.DB) error {
+	search.Default.Reset()
+
+	var posts []Post
+	if err := db.Select("id", "title", "content", "status").
+		Where("status = ?", PostStatusPublished).
+		FindInBatches(&posts, 500, func(tx *gorm.DB, batch int) error {
+			for i := range posts {
+				indexPost(&posts[i])
+			}
+			return nil
+		}).Error; err != nil {
+		return err
+	}
+
+	var comments []Comment
+	return db.Select("id", "post_id", "content", "status").
+		Where("status = ?", CommentStatusApproved).
+		FindInBatches(&comments, 500, func(tx *gorm.DB, batch int) error {
+			for i := range comments {
+				indexComment(&comments[i])
+			}
+			return nil
+		}).Error
+}
+
+func indexPost(p *Post) {
+	if !p.IsPublished() {
+		search.Default.Remove(search.KindPost, p.ID)
+		return
+	}
+	search.Default.Add(search.Document{
+		Kind:   search.KindPost,
+		ID:     p.ID,
+		PostID: p.ID,
+		Title:  p.Title,
+		Body:   p.Content,
+	})
+}
+
+func indexComment(cm *Comment) {
+	if cm.Status != CommentStatusApproved {
+		search.Default.Remove(search.KindComment, cm.ID)
+		return
+	}
+	search.Default.Add(search.Document{
+		Kind:   search.KindComment,
+		ID:     cm.ID,
+		PostID: cm.PostID,
+		Body:   cm.Content,
+	})
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	maxSlugRunes = 80
+	defaultSlug  = "post"
+)
+
+// Slugify 由标题生成 slug：保留各语言的字母和数字（中日韩文字原样保留），
+// 其余字符折叠为 "-"；拉丁字母去掉重音，全角字符转为半角
+func Slugify(title string) string {
+	if slug := SlugWords(title); slug != "" {
+		return slug
+	}
+	return defaultSlug
+}
+
+// SlugWords 执行 Slugify 的转换，不含字母和数字时返回空字符串
+func SlugWords(title string) string {
+	decomposed := norm.NFD.String(norm.NFKC.String(title))
+
+	var b strings.Builder
+	var prev rune
+	pendingDash := false
+	for _, r := range decomposed {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// 只去掉拉丁字母上的重音符号，保留假名浊音等其他组合字符
+			if !unicode.Is(unicode.Latin, prev) && b.Len() > 0 {
+				b.WriteRune(r)
+			}
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingDash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingDash = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			pendingDash = true
+		}
+		prev = r
+	}
+
+	slug := []rune(norm.NFC.String(b.String()))
+	if len(slug) > maxSlugRunes {
+		slug = slug[:maxSlugRunes]
+	}
+	return strings.Trim(string(slug), "-")
+}
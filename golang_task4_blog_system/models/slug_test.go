@@ -0,0 +1,54 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"ascii", "Hello World", "hello-world"},
+		{"punctuation collapsed", "Go: tips, tricks & more!!", "go-tips-tricks-more"},
+		{"leading and trailing punctuation", "  --Hello--  ", "hello"},
+		{"chinese kept", "Go 语言入门", "go-语言入门"},
+		{"chinese punctuation", "你好，世界！", "你好-世界"},
+		{"japanese voiced kana kept", "ガイド", "ガイド"},
+		{"korean kept", "안녕 하세요", "안녕-하세요"},
+		{"latin accents removed", "Café Crème", "cafe-creme"},
+		{"full width to half width", "ＧＯ１２３", "go123"},
+		{"digits", "Top 10 of 2024", "top-10-of-2024"},
+		{"only punctuation", "?!...", "post"},
+		{"empty", "", "post"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Slugify(tt.title); got != tt.want {
+				t.Errorf("Slugify(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSlugifyTruncates(t *testing.T) {
+	got := Slugify(strings.Repeat("长", maxSlugRunes+20))
+	if n := utf8.RuneCountInString(got); n != maxSlugRunes {
+		t.Errorf("Slugify of long title has %d runes, want %d", n, maxSlugRunes)
+	}
+
+	// 截断处恰好是分隔符时不应留下结尾的 "-"
+	got = Slugify(strings.Repeat("a", maxSlugRunes-1) + " b")
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("Slugify = %q, want no trailing dash", got)
+	}
+}
+
+func TestSlugWordsEmpty(t *testing.T) {
+	if got := SlugWords("—…—"); got != "" {
+		t.Errorf("SlugWords = %q, want empty", got)
+	}
+}
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// Tag 文章标签，与文章多对多关联；发表或修改文章时按名称自动创建
+type Tag struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"size:50;not null" json:"name"`
+	Slug      string    `gorm:"size:80;not null;uniqueIndex" json:"slug"` // 由名称生成，用于去重和按标签筛选
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	// 关联关系
+	Posts []Post `gorm:"many2many:post_tags;constraint:OnDelete:CASCADE;" json:"-"`
+}
@@ -1,18 +1,54 @@
 package models
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
 
 type User struct {
-	ID       uint   `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username string `gorm:"size:50;uniqueIndex;not null" json:"username" binding:"required"`
-	Email    string `gorm:"size:100;uniqueIndex;not null" json:"email" binding:"required,email"`
-	Password string `gorm:"size:255;not null" json:"-" binding:"required,min=6"` // json:"-" 表示不序列化到JSON
+	ID              uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username        string     `gorm:"size:50;uniqueIndex;not null" json:"username" binding:"required"`
+	Email           string     `gorm:"size:100;uniqueIndex;not null" json:"email" binding:"required,email"`
+	Password        string     `gorm:"size:255;not null" json:"-" binding:"required,min=6"` // json:"-" 表示不序列化到JSON
+	Role            string     `gorm:"size:20;not null;default:author" json:"role"`         // admin, editor, author, reader
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`                         // 为空表示邮箱未验证
+	DisplayName     string     `gorm:"size:50" json:"display_name"`
+	Bio             string     `gorm:"size:500" json:"bio"`
+	AvatarURL       string     `gorm:"size:255" json:"avatar_url"`
+	DeletedAt       *time.Time `json:"-"` // 注销时间，注销后只保留匿名化的记录供评论引用
 
 	// 关联关系
 	Posts    []Post    `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
 	Comments []Comment `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
 }
 
+// UserProfile 用户的公开资料，不包含邮箱和角色等账户信息。
+// 文章和评论的作者关联也使用它，预加载时只从 users 表读取这些字段，公开接口不会带出邮箱
+type UserProfile struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Bio         string `json:"bio"`
+	AvatarURL   string `json:"avatar_url"`
+}
+
+func (UserProfile) TableName() string {
+	return "users"
+}
+
+// Profile 返回用户的公开资料
+func (u *User) Profile() UserProfile {
+	return UserProfile{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Bio:         u.Bio,
+		AvatarURL:   u.AvatarURL,
+	}
+}
+
 // HashPassword 加密密码
 func (u *User) HashPassword() error {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(u.Password), bcrypt.DefaultCost)
@@ -23,6 +59,43 @@ func (u *User) HashPassword() error {
 	return nil
 }
 
+// 注销用户匿名化后的显示名称和用户名前缀（用户名为前缀加用户ID），
+// 注册和修改资料时不允许使用，避免冒充已注销用户
+const (
+	DeletedUserDisplayName = "已注销用户"
+	DeletedUsernamePrefix  = "deleted-"
+)
+
+// IsReservedName 用户名或显示名称是否为注销用户保留
+func IsReservedName(name string) bool {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.HasPrefix(name, DeletedUsernamePrefix) || name == DeletedUserDisplayName
+}
+
+// IsDeleted 账户是否已注销
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
+
+// IsVerified 邮箱是否已验证
+func (u *User) IsVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// Can 检查用户角色是否拥有某项权限，邮箱未验证的用户没有任何权限
+func (u *User) Can(perm string) bool {
+	return u.IsVerified() && RoleHasPermission(u.Role, perm)
+}
+
+// CanAccess 检查用户能否对某资源执行操作：
+// 拥有 action:any 权限，或者是资源所有者且拥有 action:own 权限
+func (u *User) CanAccess(ownerID uint, action string) bool {
+	if u.Can(action + ":any") {
+		return true
+	}
+	return u.ID == ownerID && u.Can(action+":own")
+}
+
 // CheckPassword 验证密码
 func (u *User) CheckPassword(password string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(u.Password), []byte(password))
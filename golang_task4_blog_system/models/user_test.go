@@ -0,0 +1,24 @@
+package models
+
+import "testing"
+
+func TestIsReservedName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"deleted-12", true},
+		{"Deleted-12", true},
+		{" deleted-", true},
+		{DeletedUserDisplayName, true},
+		{"alice", false},
+		{"undeleted-1", false},
+		{"deleted", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsReservedName(tt.name); got != tt.want {
+			t.Errorf("IsReservedName(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// 一次性令牌的用途
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+)
+
+// UserToken 邮箱验证和密码重置使用的一次性令牌，数据库中只保存令牌的哈希值。
+// Email 为签发时要验证的邮箱，验证通过后写入用户
+type UserToken struct {
+	ID        uint       `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    uint       `gorm:"not null;index" json:"user_id"`
+	Purpose   string     `gorm:"size:32;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Email     string     `gorm:"size:100;not null" json:"email"`
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+
+	// 关联关系
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;" json:"-"`
+}
+
+// IsUsable 令牌未使用且未过期
+func (t *UserToken) IsUsable(now time.Time) bool {
+	return t.UsedAt == nil && now.Before(t.ExpiresAt)
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// LockoutPolicy 登录失败锁定策略：同一账户连续失败 Threshold 次后锁定 Duration，
+// 此后每多失败一次锁定时长翻倍，最长 MaxDuration；失败计数在 Window 内没有新的失败时清零
+type LockoutPolicy struct {
+	Threshold   int
+	Duration    time.Duration
+	MaxDuration time.Duration
+	Window      time.Duration
+}
+
+// Lockout 按账户记录密码校验失败并执行渐进式锁定，nil 表示不锁定
+type Lockout struct {
+	store  Store
+	policy LockoutPolicy
+}
+
+// NewLockout 创建锁定器，Threshold 不大于 0 时返回 nil
+func NewLockout(store Store, policy LockoutPolicy) *Lockout {
+	if policy.Threshold <= 0 {
+		return nil
+	}
+	return &Lockout{store: store, policy: policy}
+}
+
+// Check 返回账户剩余的锁定时长，未锁定时为 0
+func (l *Lockout) Check(ctx context.Context, account string) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	return l.store.LockTTL(ctx, lockKey(account))
+}
+
+// Fail 记录一次校验失败，达到阈值时锁定账户并返回锁定时长
+func (l *Lockout) Fail(ctx context.Context, account string) (time.Duration, error) {
+	if l == nil {
+		return 0, nil
+	}
+	n, err := l.store.Incr(ctx, failureKey(account), l.policy.Window)
+	if err != nil {
+		return 0, err
+	}
+	if n < int64(l.policy.Threshold) {
+		return 0, nil
+	}
+
+	d := l.policy.Duration
+	for i := int64(l.policy.Threshold); i < n && d < l.policy.MaxDuration; i++ {
+		d *= 2
+	}
+	d = min(d, l.policy.MaxDuration)
+	return d, l.store.Lock(ctx, lockKey(account), d)
+}
+
+// Succeed 校验成功后清除失败计数
+func (l *Lockout) Succeed(ctx context.Context, account string) error {
+	if l == nil {
+		return nil
+	}
+	return l.store.Reset(ctx, failureKey(account))
+}
+
+// Attempt 账户未锁定时调用 verify 校验密码，失败则计数，成功则清除计数。
+// 账户已锁定或本次失败触发锁定时返回锁定时长，此时不会调用 verify 或 ok 为 false；
+// 存储出错时记录日志并按未锁定处理，避免存储故障导致无法登录
+func (l *Lockout) Attempt(ctx context.Context, account string, verify func() bool) (ok bool, locked time.Duration) {
+	locked, err := l.Check(ctx, account)
+	if err != nil {
+		slog.WarnContext(ctx, "lockout check failed", "error", err)
+	}
+	if locked > 0 {
+		return false, locked
+	}
+
+	if verify() {
+		if err := l.Succeed(ctx, account); err != nil {
+			slog.WarnContext(ctx, "lockout reset failed", "error", err)
+		}
+		return true, 0
+	}
+
+	locked, err = l.Fail(ctx, account)
+	if err != nil {
+		slog.WarnContext(ctx, "lockout record failed", "error", err)
+	}
+	return false, locked
+}
+
+func failureKey(account string) string {
+	return "lockout:failures:" + strings.ToLower(account)
+}
+
+func lockKey(account string) string {
+	return "lockout:locked:" + strings.ToLower(account)
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval 清理已过期状态的间隔
+const sweepInterval = time.Minute
+
+// MemoryStore 进程内存储，状态不在实例间共享，重启后清空
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	counters  map[string]counter
+	locks     map[string]time.Time
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // 之后桶已补满，可以丢弃
+}
+
+type counter struct {
+	n       int64
+	expires time.Time
+}
+
+// NewMemoryStore 创建进程内存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets:  make(map[string]*bucket),
+		counters: make(map[string]counter),
+		locks:    make(map[string]time.Time),
+		now:      time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	burst := float64(limit.Burst)
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst}
+		s.buckets[key] = b
+	} else {
+		b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*limit.Rate)
+	}
+	b.updated = now
+
+	res := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = retryAfter(b.tokens, limit)
+	}
+	res.Remaining = int(b.tokens)
+	b.full = now.Add(time.Duration((burst - b.tokens) / limit.Rate * float64(time.Second)))
+	return res, nil
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	c := s.counters[key]
+	if now.After(c.expires) {
+		c.n = 0
+	}
+	c.n++
+	c.expires = now.Add(ttl)
+	s.counters[key] = c
+	return c.n, nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.counters, key)
+	return nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[key] = s.now().Add(d)
+	return nil
+}
+
+func (s *MemoryStore) LockTTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	until, ok := s.locks[key]
+	if !ok {
+		return 0, nil
+	}
+	ttl := until.Sub(s.now())
+	if ttl <= 0 {
+		delete(s.locks, key)
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// sweep 定期删除已补满的令牌桶、过期的计数和锁定，调用方需持有锁
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if now.After(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+	for key, c := range s.counters {
+		if now.After(c.expires) {
+			delete(s.counters, key)
+		}
+	}
+	for key, until := range s.locks {
+		if now.After(until) {
+			delete(s.locks, key)
+		}
+	}
+}
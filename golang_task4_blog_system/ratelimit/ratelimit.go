@@ -0,0 +1,57 @@
+// Package ratelimit 提供令牌桶限流和登录失败锁定。状态保存在 Store 中，
+// 目前只有进程内的 MemoryStore，多实例部署需要实现共享的 Store
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Limit 令牌桶参数：每秒补充 Rate 个令牌，桶中最多存放 Burst 个
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Every 每 per 时长允许 requests 次请求，burst 为可以连续发出的请求数，为 0 时等于 requests
+func Every(requests int, per time.Duration, burst int) Limit {
+	if requests <= 0 || per <= 0 {
+		return Limit{}
+	}
+	if burst <= 0 {
+		burst = requests
+	}
+	return Limit{Rate: float64(requests) / per.Seconds(), Burst: burst}
+}
+
+// Enabled 零值 Limit 表示不限流
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// Result 一次取令牌的结果，RetryAfter 为被拒绝时距离下一个令牌可用的时长
+type Result struct {
+	Allowed    bool
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Store 限流和锁定状态的存储
+type Store interface {
+	// Take 从 key 对应的令牌桶中取一个令牌
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+	// Incr 计数加一并返回新值，最后一次加一后经过 ttl 计数过期
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	// Reset 清除 key 的计数
+	Reset(ctx context.Context, key string) error
+	// Lock 锁定 key，经过 d 后自动解锁
+	Lock(ctx context.Context, key string, d time.Duration) error
+	// LockTTL 返回 key 剩余的锁定时长，未锁定时为 0
+	LockTTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// retryAfter 令牌不足 1 个时，按补充速率计算还需等待的时长
+func retryAfter(tokens float64, limit Limit) time.Duration {
+	return time.Duration(math.Ceil((1 - tokens) / limit.Rate * float64(time.Second)))
+}
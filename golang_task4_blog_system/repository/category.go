@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+)
+
+func (r *postRepository) ListCategories(ctx context.Context) ([]models.Category, error) {
+	var categories []models.Category
+	err := r.db.WithContext(ctx).Order("name").Find(&categories).Error
+	return categories, err
+}
+
+func (r *postRepository) CountPublishedByCategory(ctx context.Context) (map[uint]int64, error) {
+	var counts []struct {
+		CategoryID uint
+		PostCount  int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Post{}).
+		Select("category_id, COUNT(*) AS post_count").
+		Where("status = ? AND category_id IS NOT NULL", models.PostStatusPublished).
+		Group("category_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]int64, len(counts))
+	for _, cnt := range counts {
+		result[cnt.CategoryID] = cnt.PostCount
+	}
+	return result, nil
+}
+
+func (r *postRepository) FindCategory(ctx context.Context, id uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &category, nil
+}
+
+func (r *postRepository) CategorySlugTaken(ctx context.Context, slug string, categoryID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Category{}).
+		Where("slug = ? AND id <> ?", slug, categoryID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *postRepository) CreateCategory(ctx context.Context, category *models.Category) error {
+	return translate(r.db.WithContext(ctx).Create(category).Error)
+}
+
+func (r *postRepository) UpdateCategory(ctx context.Context, category *models.Category, fields map[string]interface{}) error {
+	db := r.db.WithContext(ctx)
+	if len(fields) > 0 {
+		if err := db.Model(category).Updates(fields).Error; err != nil {
+			return translate(err)
+		}
+	}
+	return translate(db.First(category, category.ID).Error)
+}
+
+func (r *postRepository) DeleteCategory(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Category{}).
+			Where("parent_id = ?", category.ID).
+			Update("parent_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Post{}).
+			Where("category_id = ?", category.ID).
+			Update("category_id", category.ParentID).Error; err != nil {
+			return err
+		}
+		return tx.Delete(category).Error
+	})
+}
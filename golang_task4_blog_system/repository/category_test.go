@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"golang_task4_blog_system/models"
+)
+
+// TestDeleteCategory 删除分类时子分类和文章移动到上一级分类，顶级分类的子分类和文章变为未分类
+func TestDeleteCategory(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	author := createTestUser(t, db, "alice")
+	posts := NewPostRepository(db)
+
+	// 分类树：root → mid → leaf
+	create := func(slug string, parentID *uint) *models.Category {
+		t.Helper()
+		category := &models.Category{Name: slug, Slug: slug, ParentID: parentID}
+		if err := posts.CreateCategory(ctx, category); err != nil {
+			t.Fatal(err)
+		}
+		return category
+	}
+	root := create("root", nil)
+	mid := create("mid", &root.ID)
+	leaf := create("leaf", &mid.ID)
+
+	post := &models.Post{Title: "p", Content: "c", Slug: "p", UserID: author.ID, CategoryID: &mid.ID}
+	if err := posts.Create(ctx, post, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := posts.DeleteCategory(ctx, mid); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := posts.FindCategory(ctx, mid.ID); err != ErrNotFound {
+		t.Errorf("deleted category: err = %v, want ErrNotFound", err)
+	}
+	got, err := posts.FindCategory(ctx, leaf.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ParentID == nil || *got.ParentID != root.ID {
+		t.Errorf("leaf parent = %v, want %d", got.ParentID, root.ID)
+	}
+	moved, err := posts.FindByID(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved.CategoryID == nil || *moved.CategoryID != root.ID {
+		t.Errorf("post category = %v, want %d", moved.CategoryID, root.ID)
+	}
+
+	// 删除顶级分类后子分类成为顶级分类，文章不再属于任何分类
+	if err := posts.DeleteCategory(ctx, root); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := posts.FindCategory(ctx, leaf.ID); err != nil || got.ParentID != nil {
+		t.Errorf("leaf after deleting root = %+v (err %v), want top-level", got, err)
+	}
+	if moved, err := posts.FindByID(ctx, post.ID); err != nil || moved.CategoryID != nil {
+		t.Errorf("post after deleting root = %+v (err %v), want uncategorized", moved, err)
+	}
+}
+
+// TestTagCloud 标签云只统计已发布的文章，先按文章数取前 limit 个，再按要求排序
+func TestTagCloud(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	author := createTestUser(t, db, "alice")
+	posts := NewPostRepository(db)
+
+	for i, p := range []struct {
+		status string
+		tags   []string
+	}{
+		{models.PostStatusPublished, []string{"go", "web", "sql"}},
+		{models.PostStatusPublished, []string{"go", "web"}},
+		{models.PostStatusPublished, []string{"go"}},
+		{models.PostStatusDraft, []string{"sql", "draft-only"}},
+		{models.PostStatusDraft, []string{"sql"}},
+	} {
+		slug := string(rune('a' + i))
+		post := &models.Post{Title: slug, Content: "c", Slug: slug, Status: p.status, UserID: author.ID}
+		if err := posts.Create(ctx, post, p.tags); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(tags []TagCount) []string {
+		var result []string
+		for _, tag := range tags {
+			result = append(result, tag.Name)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		limit  int
+		byName bool
+		want   []string
+		counts []int64
+	}{
+		{"by count", 10, false, []string{"go", "web", "sql"}, []int64{3, 2, 1}},
+		{"limit keeps most used", 2, false, []string{"go", "web"}, []int64{3, 2}},
+		{"limit then by name", 2, true, []string{"go", "web"}, []int64{3, 2}},
+		{"by name", 10, true, []string{"go", "sql", "web"}, []int64{3, 1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tags, err := posts.TagCloud(ctx, tt.limit, tt.byName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := names(tags); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Fatalf("tags = %v, want %v", got, tt.want)
+			}
+			for i, tag := range tags {
+				if tag.PostCount != tt.counts[i] {
+					t.Errorf("%s count = %d, want %d", tag.Name, tag.PostCount, tt.counts[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"golang_task4_blog_system/models"
+	"log/slog"
+
+	"gorm.io/gorm"
+)
+
+// CommentRepository 评论数据访问接口
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	FindByID(ctx context.Context, id uint) (*models.Comment, error)
+	// FindWithUser 查找评论并加载作者
+	FindWithUser(ctx context.Context, id uint) (*models.Comment, error)
+	// FindDetail 查找评论并加载作者和所属文章
+	FindDetail(ctx context.Context, id uint) (*models.Comment, error)
+	// ListApproved 返回文章已审核通过的当前页评论（包含作者）和评论总数，
+	// rootsOnly 为 true 时只包含顶层评论
+	ListApproved(ctx context.Context, postID uint, rootsOnly bool, page Scope) ([]models.Comment, int64, error)
+	// ApprovedReplies 返回这些评论已审核通过的直接回复（包含作者），按创建时间排序
+	ApprovedReplies(ctx context.Context, parentIDs []uint) ([]models.Comment, error)
+	// CountApprovedReplies 统计每条评论已审核通过的直接回复数，没有回复的评论不在结果中
+	CountApprovedReplies(ctx context.Context, parentIDs []uint) (map[uint]int64, error)
+	// CountReplies 统计评论的直接回复数（包含未审核的）
+	CountReplies(ctx context.Context, id uint) (int64, error)
+	Save(ctx context.Context, comment *models.Comment) error
+	Delete(ctx context.Context, comment *models.Comment) error
+	// ListByUser 返回用户的全部评论（包含作者和所属文章），按创建时间倒序
+	ListByUser(ctx context.Context, userID uint) ([]models.Comment, int64, error)
+	// EachByUser 按ID顺序分批读取用户的全部评论（包含未审核的），每批调用一次 fn
+	EachByUser(ctx context.Context, userID uint, fn func(comments []models.Comment) error) error
+	// FindByIDs 查找这些ID对应的评论，不存在的ID不在结果中
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Comment, error)
+	// UpdateStatus 把这些评论的审核状态改为 status 并同步搜索索引，返回更新的行数
+	UpdateStatus(ctx context.Context, ids []uint, status string) (int64, error)
+	// ListForModeration 返回符合条件的当前页评论（包含作者）和评论总数，用于审核队列
+	ListForModeration(ctx context.Context, filter ModerationFilter, page Scope) ([]models.Comment, int64, error)
+	// ApprovedIDs 返回 ids 中已审核通过的评论ID
+	ApprovedIDs(ctx context.Context, ids []uint) ([]uint, error)
+}
+
+// ModerationFilter 审核队列的筛选条件，零值字段不参与筛选
+type ModerationFilter struct {
+	Status       string
+	PostID       uint
+	PostAuthorID uint // 只包含该用户文章下的评论
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository 创建基于 GORM 的评论仓储
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Create(comment).Error
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	return r.first(r.db.WithContext(ctx), id)
+}
+
+func (r *commentRepository) FindWithUser(ctx context.Context, id uint) (*models.Comment, error) {
+	return r.first(r.db.WithContext(ctx).Preload("User"), id)
+}
+
+func (r *commentRepository) FindDetail(ctx context.Context, id uint) (*models.Comment, error) {
+	return r.first(r.db.WithContext(ctx).Preload("User").Preload("Post"), id)
+}
+
+func (r *commentRepository) first(db *gorm.DB, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := db.First(&comment, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) ListApproved(ctx context.Context, postID uint, rootsOnly bool, page Scope) ([]models.Comment, int64, error) {
+	query := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Where("post_id = ? AND status = ?", postID, models.CommentStatusApproved)
+	if rootsOnly {
+		query = query.Where("parent_id IS NULL")
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []models.Comment
+	if err := query.Scopes(page).Preload("User").Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) ApprovedReplies(ctx context.Context, parentIDs []uint) ([]models.Comment, error) {
+	var replies []models.Comment
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("parent_id IN ? AND status = ?", parentIDs, models.CommentStatusApproved).
+		Order("created_at ASC").Order("id ASC").
+		Find(&replies).Error
+	return replies, err
+}
+
+func (r *commentRepository) CountApprovedReplies(ctx context.Context, parentIDs []uint) (map[uint]int64, error) {
+	var counts []struct {
+		ParentID uint
+		Total    int64
+	}
+	if err := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Select("parent_id, COUNT(*) AS total").
+		Where("parent_id IN ? AND status = ?", parentIDs, models.CommentStatusApproved).
+		Group("parent_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]int64, len(counts))
+	for _, cnt := range counts {
+		result[cnt.ParentID] = cnt.Total
+	}
+	return result, nil
+}
+
+func (r *commentRepository) CountReplies(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Comment{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+func (r *commentRepository) Save(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Save(comment).Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, comment *models.Comment) error {
+	return r.db.WithContext(ctx).Delete(comment).Error
+}
+
+func (r *commentRepository) ListByUser(ctx context.Context, userID uint) ([]models.Comment, int64, error) {
+	db := r.db.WithContext(ctx)
+
+	var total int64
+	if err := db.Model(&models.Comment{}).Where("user_id = ?", userID).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []models.Comment
+	if err := db.Preload("User").Preload("Post").
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) EachByUser(ctx context.Context, userID uint, fn func(comments []models.Comment) error) error {
+	var comments []models.Comment
+	return r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		FindInBatches(&comments, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(comments)
+		}).Error
+}
+
+func (r *commentRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&comments).Error
+	return comments, err
+}
+
+func (r *commentRepository) UpdateStatus(ctx context.Context, ids []uint, status string) (int64, error) {
+	db := r.db.WithContext(ctx)
+	result := db.Model(&models.Comment{}).Where("id IN ?", ids).Update("status", status)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// 批量更新不会触发模型钩子，手动同步搜索索引
+	if err := models.SyncCommentSearch(db, ids...); err != nil {
+		slog.ErrorContext(ctx, "failed to sync comment search index", "error", err)
+	}
+	return result.RowsAffected, nil
+}
+
+func (r *commentRepository) ListForModeration(ctx context.Context, filter ModerationFilter, page Scope) ([]models.Comment, int64, error) {
+	db := r.db.WithContext(ctx)
+	query := db.Model(&models.Comment{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.PostID != 0 {
+		query = query.Where("post_id = ?", filter.PostID)
+	}
+	if filter.PostAuthorID != 0 {
+		query = query.Where("post_id IN (?)",
+			db.Model(&models.Post{}).Select("id").Where("user_id = ?", filter.PostAuthorID))
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var comments []models.Comment
+	if err := query.Scopes(page).Preload("User").Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+func (r *commentRepository) ApprovedIDs(ctx context.Context, ids []uint) ([]uint, error) {
+	var approved []uint
+	if len(ids) == 0 {
+		return approved, nil
+	}
+	err := r.db.WithContext(ctx).Model(&models.Comment{}).
+		Where("id IN ? AND status = ?", ids, models.CommentStatusApproved).
+		Pluck("id", &approved).Error
+	return approved, err
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golang_task4_blog_system/models"
+)
+
+// TestApprovedReplies 回复列表和回复数只包含已审核通过的直接回复
+func TestApprovedReplies(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	author := createTestUser(t, db, "alice")
+	post := &models.Post{Title: "Hello", Content: "c", Slug: "hello", Status: models.PostStatusPublished, UserID: author.ID}
+	if err := NewPostRepository(db).Create(ctx, post, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	comments := NewCommentRepository(db)
+	create := func(parentID *uint, status string) *models.Comment {
+		t.Helper()
+		comment := &models.Comment{Content: "c", Status: status, UserID: author.ID, PostID: post.ID, ParentID: parentID}
+		if err := comments.Create(ctx, comment); err != nil {
+			t.Fatal(err)
+		}
+		return comment
+	}
+	root := create(nil, models.CommentStatusApproved)
+	approved := create(&root.ID, models.CommentStatusApproved)
+	create(&root.ID, models.CommentStatusPending)
+	create(&root.ID, models.CommentStatusRejected)
+	create(&approved.ID, models.CommentStatusApproved)
+
+	counts, err := comments.CountApprovedReplies(ctx, []uint{root.ID, approved.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[root.ID] != 1 || counts[approved.ID] != 1 {
+		t.Errorf("counts = %v, want 1 approved reply each", counts)
+	}
+
+	replies, err := comments.ApprovedReplies(ctx, []uint{root.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(replies) != 1 || replies[0].ID != approved.ID {
+		t.Errorf("replies = %v, want only comment %d", replies, approved.ID)
+	}
+
+	if total, err := comments.CountReplies(ctx, root.ID); err != nil || total != 3 {
+		t.Errorf("CountReplies = %d (err %v), want 3 including hidden", total, err)
+	}
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"golang_task4_blog_system/models"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostFilter 文章列表的筛选条件，零值字段不参与筛选；
+// 不存在的标签或分类筛选结果为空
+type PostFilter struct {
+	Status   string
+	UserID   uint
+	Author   string     // 作者用户名
+	From     *time.Time // 创建时间下限（包含）
+	To       *time.Time // 创建时间上限（不包含）
+	Title    string     // 标题关键字
+	Tags     []string   // 标签名或 slug，需同时包含所有标签
+	Category string     // 分类ID或 slug，包含子分类
+}
+
+// PostChanges 文章的更新内容
+type PostChanges struct {
+	Fields      map[string]interface{} // 直接更新的列
+	ReplaceTags bool                   // 为 true 时把标签替换为 Tags，Tags 为空则清空
+	Tags        []string
+	Slug        string // 非空时以此为基础生成唯一 slug，旧 slug 记入历史
+}
+
+// PostRepository 文章数据访问接口
+type PostRepository interface {
+	// Create 创建文章并设置标签，post.Slug 为基础 slug，冲突时自动追加序号
+	Create(ctx context.Context, post *models.Post, tags []string) error
+	FindByID(ctx context.Context, id uint) (*models.Post, error)
+	// FindWithRelations 查找文章并加载用户、分类和标签
+	FindWithRelations(ctx context.Context, id uint) (*models.Post, error)
+	// FindDetail 在 FindWithRelations 的基础上加载已审核通过的评论
+	FindDetail(ctx context.Context, id uint) (*models.Post, error)
+	FindDetailBySlug(ctx context.Context, slug string) (*models.Post, error)
+	// FindByHistorySlug 通过文章用过的旧 slug 查找文章
+	FindByHistorySlug(ctx context.Context, slug string) (*models.Post, error)
+	// List 返回符合条件的当前页文章（包含用户、分类和标签）和文章总数
+	List(ctx context.Context, filter PostFilter, page Scope) ([]models.Post, int64, error)
+	Update(ctx context.Context, post *models.Post, changes PostChanges) error
+	Delete(ctx context.Context, post *models.Post) error
+	CategoryExists(ctx context.Context, id uint) (bool, error)
+	// EachByUser 按ID顺序分批读取用户的全部文章（包含分类和标签），每批调用一次 fn
+	EachByUser(ctx context.Context, userID uint, fn func(posts []models.Post) error) error
+	// FindPublished 查找这些ID中已发布的文章（包含用户）
+	FindPublished(ctx context.Context, ids []uint) ([]models.Post, error)
+	// PublishDue 将发布时间不晚于 now 的定时文章改为已发布，返回发布的文章数
+	PublishDue(ctx context.Context, now time.Time) (int64, error)
+	// TagCloud 按已发布文章数取前 limit 个标签，没有已发布文章的标签不包含；
+	// byName 为 true 时结果按名称排序，否则按文章数倒序
+	TagCloud(ctx context.Context, limit int, byName bool) ([]TagCount, error)
+
+	// ListCategories 返回全部分类，按名称排序
+	ListCategories(ctx context.Context) ([]models.Category, error)
+	// CountPublishedByCategory 统计每个分类直接包含的已发布文章数，没有文章的分类不在结果中
+	CountPublishedByCategory(ctx context.Context) (map[uint]int64, error)
+	FindCategory(ctx context.Context, id uint) (*models.Category, error)
+	// CategorySlugTaken slug 是否已被 categoryID 以外的分类使用
+	CategorySlugTaken(ctx context.Context, slug string, categoryID uint) (bool, error)
+	// CreateCategory 创建分类，slug 重复时返回 ErrDuplicated
+	CreateCategory(ctx context.Context, category *models.Category) error
+	// UpdateCategory 更新 fields 中的列（列名到值）并重新加载 category
+	UpdateCategory(ctx context.Context, category *models.Category, fields map[string]interface{}) error
+	// DeleteCategory 删除分类，其子分类和文章移动到上一级分类
+	DeleteCategory(ctx context.Context, category *models.Category) error
+}
+
+type postRepository struct {
+	db *gorm.DB
+}
+
+// NewPostRepository 创建基于 GORM 的文章仓储
+func NewPostRepository(db *gorm.DB) PostRepository {
+	return &postRepository{db: db}
+}
+
+func (r *postRepository) Create(ctx context.Context, post *models.Post, tags []string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		slug, err := uniqueSlug(tx, post.Slug, 0)
+		if err != nil {
+			return err
+		}
+		post.Slug = slug
+		if err := tx.Create(post).Error; err != nil {
+			return err
+		}
+		return setPostTags(tx, post, tags)
+	})
+}
+
+func (r *postRepository) FindByID(ctx context.Context, id uint) (*models.Post, error) {
+	return r.first(r.db.WithContext(ctx), "id = ?", id)
+}
+
+func (r *postRepository) FindWithRelations(ctx context.Context, id uint) (*models.Post, error) {
+	return r.first(withRelations(r.db.WithContext(ctx)), "id = ?", id)
+}
+
+func (r *postRepository) FindDetail(ctx context.Context, id uint) (*models.Post, error) {
+	return r.first(withDetail(r.db.WithContext(ctx)), "id = ?", id)
+}
+
+func (r *postRepository) FindDetailBySlug(ctx context.Context, slug string) (*models.Post, error) {
+	return r.first(withDetail(r.db.WithContext(ctx)), "slug = ?", slug)
+}
+
+func (r *postRepository) FindByHistorySlug(ctx context.Context, slug string) (*models.Post, error) {
+	var history models.PostSlug
+	if err := r.db.WithContext(ctx).Preload("Post").Where("slug = ?", slug).First(&history).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &history.Post, nil
+}
+
+func (r *postRepository) first(db *gorm.DB, query string, arg interface{}) (*models.Post, error) {
+	var post models.Post
+	if err := db.Where(query, arg).First(&post).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &post, nil
+}
+
+func (r *postRepository) List(ctx context.Context, filter PostFilter, page Scope) ([]models.Post, int64, error) {
+	db := r.db.WithContext(ctx)
+	query, err := filterPosts(db, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var posts []models.Post
+	if err := withRelations(query.Scopes(page)).Find(&posts).Error; err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+func (r *postRepository) Update(ctx context.Context, post *models.Post, changes PostChanges) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(post).Updates(changes.Fields).Error; err != nil {
+			return err
+		}
+		if changes.ReplaceTags {
+			if err := setPostTags(tx, post, changes.Tags); err != nil {
+				return err
+			}
+		}
+		if changes.Slug == "" {
+			return nil
+		}
+		slug, err := uniqueSlug(tx, changes.Slug, post.ID)
+		if err != nil {
+			return err
+		}
+		return changePostSlug(tx, post, slug)
+	})
+}
+
+// Delete 删除文章，关联的评论由外键约束级联删除
+func (r *postRepository) Delete(ctx context.Context, post *models.Post) error {
+	return r.db.WithContext(ctx).Delete(post).Error
+}
+
+func (r *postRepository) CategoryExists(ctx context.Context, id uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Category{}).Where("id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *postRepository) EachByUser(ctx context.Context, userID uint, fn func(posts []models.Post) error) error {
+	var posts []models.Post
+	return r.db.WithContext(ctx).Preload("Category").Preload("Tags").
+		Where("user_id = ?", userID).
+		FindInBatches(&posts, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(posts)
+		}).Error
+}
+
+func (r *postRepository) FindPublished(ctx context.Context, ids []uint) ([]models.Post, error) {
+	var posts []models.Post
+	if len(ids) == 0 {
+		return posts, nil
+	}
+	err := r.db.WithContext(ctx).Preload("User").
+		Where("id IN ? AND status = ?", ids, models.PostStatusPublished).
+		Find(&posts).Error
+	return posts, err
+}
+
+func (r *postRepository) PublishDue(ctx context.Context, now time.Time) (int64, error) {
+	db := r.db.WithContext(ctx)
+	var ids []uint
+	if err := db.Model(&models.Post{}).
+		Where("status = ? AND publish_at <= ?", models.PostStatusScheduled, now.UTC()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	result := db.Model(&models.Post{}).
+		Where("id IN ? AND status = ?", ids, models.PostStatusScheduled).
+		Updates(map[string]interface{}{
+			"status":       models.PostStatusPublished,
+			"published_at": gorm.Expr("publish_at"),
+		})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	// 批量更新不会触发模型钩子，手动同步搜索索引
+	if err := models.SyncPostSearch(db, ids...); err != nil {
+		slog.ErrorContext(ctx, "failed to sync post search index", "error", err)
+	}
+	return result.RowsAffected, nil
+}
+
+// withRelations 预加载文章列表需要的用户、分类和标签
+func withRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("User").Preload("Category").Preload("Tags")
+}
+
+// withDetail 文章详情查询，包含用户信息和已审核通过的评论
+func withDetail(db *gorm.DB) *gorm.DB {
+	return withRelations(db).
+		Preload("Comments", "status = ?", models.CommentStatusApproved).
+		Preload("Comments.User")
+}
+
+func filterPosts(db *gorm.DB, filter PostFilter) (*gorm.DB, error) {
+	query := db.Model(&models.Post{})
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.Author != "" {
+		query = query.Where("user_id IN (?)",
+			db.Model(&models.User{}).Select("id").Where("username = ?", filter.Author))
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if filter.Title != "" {
+		query = query.Where("title LIKE ? ESCAPE '!'", "%"+escapeLike(filter.Title)+"%")
+	}
+	for _, tag := range filter.Tags {
+		query = query.Where("posts.id IN (?)",
+			db.Table("post_tags").
+				Select("post_tags.post_id").
+				Joins("JOIN tags ON tags.id = post_tags.tag_id").
+				Where("tags.slug = ?", models.SlugWords(tag)))
+	}
+	if category := strings.TrimSpace(filter.Category); category != "" {
+		ids, err := categoryWithDescendants(db, category)
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("posts.category_id IN ?", ids)
+	}
+	return query, nil
+}
+
+// categoryWithDescendants 返回分类及其全部子分类的ID，category 可以是ID或 slug
+func categoryWithDescendants(db *gorm.DB, category string) ([]uint, error) {
+	var categories []models.Category
+	if err := db.Select("id", "slug", "parent_id").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	var root uint
+	id, idErr := strconv.ParseUint(category, 10, 32)
+	children := make(map[uint][]uint)
+	for _, cat := range categories {
+		// ID 优先于 slug 匹配
+		if idErr == nil && cat.ID == uint(id) {
+			root = cat.ID
+		} else if root == 0 && cat.Slug == category {
+			root = cat.ID
+		}
+		if cat.ParentID != nil {
+			children[*cat.ParentID] = append(children[*cat.ParentID], cat.ID)
+		}
+	}
+	if root == 0 {
+		return nil, nil
+	}
+
+	ids := []uint{root}
+	for i := 0; i < len(ids); i++ {
+		ids = append(ids, children[ids[i]]...)
+	}
+	return ids, nil
+}
+
+// escapeLike 转义 LIKE 模式中的通配符，转义符为 "!"，查询中需配合 ESCAPE '!' 使用
+// （SQLite 没有默认转义符，MySQL 和 PostgreSQL 对反斜杠的处理不一致）
+func escapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
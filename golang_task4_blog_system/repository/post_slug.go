@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+)
+
+const maxSlugAttempts = 1000
+
+// uniqueSlug 在 base 基础上追加 -2、-3 … 直到不与其他文章的当前或历史 slug 冲突，
+// postID 为当前文章ID（新建时为 0），自己的历史 slug 可以重新使用
+func uniqueSlug(db *gorm.DB, base string, postID uint) (string, error) {
+	candidate := base
+	for i := 2; i <= maxSlugAttempts; i++ {
+		taken, err := slugTaken(db, candidate, postID)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", base, i)
+	}
+	return "", fmt.Errorf("no available slug for %q", base)
+}
+
+func slugTaken(db *gorm.DB, slug string, postID uint) (bool, error) {
+	var count int64
+	if err := db.Model(&models.Post{}).
+		Where("slug = ? AND id <> ?", slug, postID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := db.Model(&models.PostSlug{}).
+		Where("slug = ? AND post_id <> ?", slug, postID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// changePostSlug 把文章当前 slug 记入历史并切换到新 slug；
+// 新 slug 如果是该文章以前用过的，则从历史中移除
+func changePostSlug(tx *gorm.DB, post *models.Post, slug string) error {
+	if post.Slug == slug {
+		return nil
+	}
+
+	if err := tx.Where("post_id = ? AND slug = ?", post.ID, slug).
+		Delete(&models.PostSlug{}).Error; err != nil {
+		return err
+	}
+	if post.Slug != "" {
+		if err := tx.Create(&models.PostSlug{PostID: post.ID, Slug: post.Slug}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Model(post).Update("slug", slug).Error; err != nil {
+		return err
+	}
+	post.Slug = slug
+	return nil
+}
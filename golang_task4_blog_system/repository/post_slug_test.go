@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"golang_task4_blog_system/models"
+)
+
+func TestPostSlugCollisions(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	ctx := context.Background()
+	user := createTestUser(t, db, "alice")
+
+	create := func(title string) *models.Post {
+		t.Helper()
+		post := &models.Post{Title: title, Content: "c", Slug: models.Slugify(title), UserID: user.ID}
+		if err := repo.Create(ctx, post, nil); err != nil {
+			t.Fatal(err)
+		}
+		return post
+	}
+
+	first := create("Hello, World")
+	second := create("Hello World!")
+	third := create("hello world")
+	for i, want := range []string{"hello-world", "hello-world-2", "hello-world-3"} {
+		if got := []*models.Post{first, second, third}[i].Slug; got != want {
+			t.Errorf("post %d slug = %q, want %q", i+1, got, want)
+		}
+	}
+
+	// 改名后旧 slug 进入历史，仍然不能被其他文章占用
+	if err := repo.Update(ctx, first, PostChanges{Fields: map[string]interface{}{"title": "Renamed"}, Slug: "renamed"}); err != nil {
+		t.Fatal(err)
+	}
+	if first.Slug != "renamed" {
+		t.Fatalf("renamed slug = %q", first.Slug)
+	}
+	if fourth := create("Hello World"); fourth.Slug != "hello-world-4" {
+		t.Errorf("slug after rename = %q, want hello-world-4", fourth.Slug)
+	}
+
+	// 文章自己的历史 slug 可以重新使用
+	if err := repo.Update(ctx, first, PostChanges{Slug: "hello-world"}); err != nil {
+		t.Fatal(err)
+	}
+	if first.Slug != "hello-world" {
+		t.Errorf("reclaimed slug = %q, want hello-world", first.Slug)
+	}
+}
+
+func TestPostSlugCJK(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewPostRepository(db)
+	user := createTestUser(t, db, "bob")
+
+	var slugs []string
+	for i := 0; i < 2; i++ {
+		post := &models.Post{Title: "你好，世界", Content: "c", Slug: models.Slugify("你好，世界"), UserID: user.ID}
+		if err := repo.Create(context.Background(), post, nil); err != nil {
+			t.Fatal(err)
+		}
+		slugs = append(slugs, post.Slug)
+	}
+	if slugs[0] != "你好-世界" || slugs[1] != "你好-世界-2" {
+		t.Errorf("slugs = %q", slugs)
+	}
+}
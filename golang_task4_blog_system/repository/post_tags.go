@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// findOrCreateTags 按名称查找标签，不存在的自动创建；names 需先经过去重整理
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	slugs := make([]string, len(names))
+	for i, name := range names {
+		slugs[i] = models.SlugWords(name)
+	}
+
+	var existing []models.Tag
+	if err := tx.Where("slug IN ?", slugs).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+	bySlug := make(map[string]models.Tag, len(existing))
+	for _, t := range existing {
+		bySlug[t.Slug] = t
+	}
+
+	var missing []models.Tag
+	for i, slug := range slugs {
+		if _, ok := bySlug[slug]; !ok {
+			missing = append(missing, models.Tag{Name: names[i], Slug: slug})
+		}
+	}
+	if len(missing) > 0 {
+		// 并发创建同名标签时忽略唯一索引冲突，随后统一重新查询
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+			return nil, err
+		}
+		var created []models.Tag
+		if err := tx.Where("slug IN ?", slugs).Find(&created).Error; err != nil {
+			return nil, err
+		}
+		for _, t := range created {
+			bySlug[t.Slug] = t
+		}
+	}
+
+	// 按请求中的顺序返回
+	tags := make([]models.Tag, 0, len(slugs))
+	for _, slug := range slugs {
+		tag, ok := bySlug[slug]
+		if !ok {
+			return nil, fmt.Errorf("tag %q not found after create", slug)
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// setPostTags 把文章的标签替换为 names，names 为空时清空标签
+func setPostTags(tx *gorm.DB, post *models.Post, names []string) error {
+	tags, err := findOrCreateTags(tx, names)
+	if err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return tx.Model(post).Association("Tags").Clear()
+	}
+	return tx.Model(post).Association("Tags").Replace(tags)
+}
+
+// TagCount 标签及其已发布文章数
+type TagCount struct {
+	ID        uint
+	Name      string
+	Slug      string
+	PostCount int64
+}
+
+func (r *postRepository) TagCloud(ctx context.Context, limit int, byName bool) ([]TagCount, error) {
+	order := "post_count DESC, tags.name"
+	if byName {
+		order = "tags.name"
+	}
+
+	// 先按文章数取出前 limit 个标签，再按要求排序
+	db := r.db.WithContext(ctx)
+	top := db.Model(&models.Tag{}).
+		Select("tags.id, tags.name, tags.slug, COUNT(posts.id) AS post_count").
+		Joins("JOIN post_tags ON post_tags.tag_id = tags.id").
+		Joins("JOIN posts ON posts.id = post_tags.post_id AND posts.status = ?", models.PostStatusPublished).
+		Group("tags.id, tags.name, tags.slug").
+		Order("post_count DESC, tags.name").
+		Limit(limit)
+
+	var tags []TagCount
+	err := db.Table("(?) AS tags", top).Order(order).Scan(&tags).Error
+	return tags, err
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+)
+
+// TestAuthorIsPublicProfile 文章和评论中的作者只包含公开资料，不能带出邮箱和角色
+func TestAuthorIsPublicProfile(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	author := createTestUser(t, db, "alice")
+	db.Model(author).Update("display_name", "Alice")
+
+	posts := NewPostRepository(db)
+	comments := NewCommentRepository(db)
+	post := &models.Post{Title: "Hello", Content: "c", Slug: "hello", UserID: author.ID}
+	if err := posts.Create(ctx, post, nil); err != nil {
+		t.Fatal(err)
+	}
+	comment := &models.Comment{Content: "hi", Status: models.CommentStatusApproved, UserID: author.ID, PostID: post.ID}
+	if err := comments.Create(ctx, comment); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := posts.FindDetail(ctx, post.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list, _, err := posts.List(ctx, PostFilter{}, func(db *gorm.DB) *gorm.DB { return db })
+	if err != nil {
+		t.Fatal(err)
+	}
+	approved, _, err := comments.ListApproved(ctx, post.ID, false, func(db *gorm.DB) *gorm.DB { return db })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		value any
+	}{
+		{"post detail", detail},
+		{"post list", list},
+		{"comment list", approved},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body := string(data)
+			if !strings.Contains(body, `"username":"alice"`) || !strings.Contains(body, `"display_name":"Alice"`) {
+				t.Errorf("author profile missing: %s", body)
+			}
+			for _, leaked := range []string{"alice@example.com", `"email"`, `"role"`} {
+				if strings.Contains(body, leaked) {
+					t.Errorf("response leaks %s: %s", leaked, body)
+				}
+			}
+		})
+	}
+}
+
+// TestPublishDue 到期的定时文章被发布，未到期的保持定时；比较时忽略 now 的时区
+func TestPublishDue(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	author := createTestUser(t, db, "alice")
+	posts := NewPostRepository(db)
+
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	due := now.Add(-time.Minute)
+	later := now.Add(time.Hour)
+	duePost := &models.Post{Title: "due", Content: "c", Slug: "due", Status: models.PostStatusScheduled, PublishAt: &due, UserID: author.ID}
+	laterPost := &models.Post{Title: "later", Content: "c", Slug: "later", Status: models.PostStatusScheduled, PublishAt: &later, UserID: author.ID}
+	for _, post := range []*models.Post{duePost, laterPost} {
+		if err := posts.Create(ctx, post, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 同一时刻的 UTC-5 表示，SQLite 按字符串比较时会排在 due 之前
+	n, err := posts.PublishDue(ctx, now.In(time.FixedZone("EST", -5*3600)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Fatalf("published %d posts, want 1", n)
+	}
+
+	got, err := posts.FindByID(ctx, duePost.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.PostStatusPublished || got.PublishedAt == nil || !got.PublishedAt.Equal(due) {
+		t.Errorf("due post = %s published_at %v, want published at %v", got.Status, got.PublishedAt, due)
+	}
+	got, err = posts.FindByID(ctx, laterPost.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != models.PostStatusScheduled {
+		t.Errorf("later post status = %s, want scheduled", got.Status)
+	}
+
+	if n, err := posts.PublishDue(ctx, now); err != nil || n != 0 {
+		t.Errorf("second run published %d posts (err %v), want 0", n, err)
+	}
+}
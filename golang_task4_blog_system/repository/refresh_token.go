@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"golang_task4_blog_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository 刷新令牌的数据访问接口。
+// 同一次登录签发的令牌属于同一令牌族（FamilyID），每次刷新时旧令牌被新令牌替换
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error)
+	// Rotate 保存 next 并把 current 标记为已被其替换，current 已被吊销或替换时返回 ErrNotFound
+	Rotate(ctx context.Context, current, next *models.RefreshToken, at time.Time) error
+	// RevokeFamily 吊销令牌族中尚未吊销的全部令牌
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+}
+
+type refreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 创建基于 GORM 的刷新令牌仓储
+func NewRefreshTokenRepository(db *gorm.DB) RefreshTokenRepository {
+	return &refreshTokenRepository{db: db}
+}
+
+func (r *refreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	return translate(r.db.WithContext(ctx).Create(token).Error)
+}
+
+func (r *refreshTokenRepository) FindByHash(ctx context.Context, hash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	if err := r.db.WithContext(ctx).Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &token, nil
+}
+
+func (r *refreshTokenRepository) Rotate(ctx context.Context, current, next *models.RefreshToken, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(next).Error; err != nil {
+			return translate(err)
+		}
+
+		// 条件更新，防止同一令牌被并发使用两次
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL AND replaced_by_id IS NULL", current.ID).
+			Updates(map[string]interface{}{
+				"revoked_at":     at,
+				"replaced_by_id": next.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return ErrNotFound
+		}
+		current.RevokedAt = &at
+		current.ReplacedByID = &next.ID
+		return nil
+	})
+}
+
+func (r *refreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/models"
+)
+
+func TestRefreshTokenRotate(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	alice := createTestUser(t, db, "alice")
+	tokens := NewRefreshTokenRepository(db)
+
+	token := func(hash string) *models.RefreshToken {
+		return &models.RefreshToken{UserID: alice.ID, FamilyID: "family", TokenHash: hash, ExpiresAt: time.Now().Add(time.Hour)}
+	}
+	first := token("h1")
+	if err := tokens.Create(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同一令牌只能轮换一次，第二次（并发重放）返回 ErrNotFound
+	stale, err := tokens.FindByHash(ctx, "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second := token("h2")
+	if err := tokens.Rotate(ctx, first, second, time.Now()); err != nil {
+		t.Fatalf("Rotate: %v", err)
+	}
+	if err := tokens.Rotate(ctx, stale, token("h3"), time.Now()); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Rotate = %v, want ErrNotFound", err)
+	}
+	if _, err := tokens.FindByHash(ctx, "h3"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("failed rotation kept its new token: %v", err)
+	}
+
+	got, err := tokens.FindByHash(ctx, "h1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IsActive(time.Now()) || got.ReplacedByID == nil || *got.ReplacedByID != second.ID {
+		t.Errorf("rotated token = %+v, want replaced by %d", got, second.ID)
+	}
+
+	if err := tokens.RevokeFamily(ctx, "family", time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := tokens.FindByHash(ctx, "h2"); got.RevokedAt == nil {
+		t.Error("RevokeFamily left the current token active")
+	}
+}
@@ -0,0 +1,32 @@
+// Package repository 封装博客数据的持久化操作，上层通过接口访问，便于替换为内存实现
+package repository
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// 各仓储实现统一返回的错误
+var (
+	ErrNotFound   = errors.New("record not found")
+	ErrDuplicated = errors.New("duplicated key") // 违反唯一约束
+)
+
+// batchSize 分批读取（EachByUser 等）时每批的记录数
+const batchSize = 200
+
+// Scope 列表查询的排序和分页条件，由调用方根据请求参数构造，
+// 形式同 gorm.DB.Scopes 的参数，内存实现可以忽略
+type Scope func(db *gorm.DB) *gorm.DB
+
+// translate 把 GORM 的错误转换为仓储错误，需开启 gorm.Config.TranslateError
+func translate(err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicated
+	}
+	return err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"testing"
+
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/migrations"
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+)
+
+// newTestDB 打开执行过全部迁移的 SQLite 内存数据库，测试结束时关闭
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// createTestUser 创建测试用户
+func createTestUser(t *testing.T, db *gorm.DB, username string) *models.User {
+	t.Helper()
+	user := &models.User{Username: username, Password: "x", Email: username + "@example.com", Role: models.RoleAuthor}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"golang_task4_blog_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserRepository 用户数据访问接口
+type UserRepository interface {
+	Create(ctx context.Context, user *models.User) error
+	FindByID(ctx context.Context, id uint) (*models.User, error)
+	FindByUsername(ctx context.Context, username string) (*models.User, error)
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	// FindConflicts 查找用户名或邮箱与参数相同的用户
+	FindConflicts(ctx context.Context, username, email string) ([]models.User, error)
+	UpdateRole(ctx context.Context, user *models.User, role string) error
+	// UpdateProfile 更新 fields 中的资料字段（列名到值），并同步到 user
+	UpdateProfile(ctx context.Context, user *models.User, fields map[string]interface{}) error
+	// UpdatePassword 保存新的密码哈希，并吊销该用户的全部刷新令牌
+	UpdatePassword(ctx context.Context, user *models.User, passwordHash string) error
+	// Anonymize 注销用户：删除其令牌和个人资料，用户记录改为无法登录的匿名占位，
+	// 其文章和评论保留并显示为已注销用户
+	Anonymize(ctx context.Context, user *models.User, at time.Time) error
+}
+
+type userRepository struct {
+	db *gorm.DB
+}
+
+// NewUserRepository 创建基于 GORM 的用户仓储
+func NewUserRepository(db *gorm.DB) UserRepository {
+	return &userRepository{db: db}
+}
+
+// Create 创建用户，用户名或邮箱重复时返回 ErrDuplicated
+func (r *userRepository) Create(ctx context.Context, user *models.User) error {
+	return translate(r.db.WithContext(ctx).Create(user).Error)
+}
+
+func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByUsername(ctx context.Context, username string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	var user models.User
+	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+		return nil, translate(err)
+	}
+	return &user, nil
+}
+
+func (r *userRepository) FindConflicts(ctx context.Context, username, email string) ([]models.User, error) {
+	var users []models.User
+	err := r.db.WithContext(ctx).Select("username", "email").
+		Where("username = ? OR email = ?", username, email).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) UpdateRole(ctx context.Context, user *models.User, role string) error {
+	return r.db.WithContext(ctx).Model(user).Update("role", role).Error
+}
+
+func (r *userRepository) UpdateProfile(ctx context.Context, user *models.User, fields map[string]interface{}) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Model(user).Updates(fields).Error
+}
+
+func (r *userRepository) UpdatePassword(ctx context.Context, user *models.User, passwordHash string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(user).Update("password", passwordHash).Error; err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, user.ID, time.Now())
+	})
+}
+
+func (r *userRepository) Anonymize(ctx context.Context, user *models.User, at time.Time) error {
+	// 文章和评论都保留（其他用户在文章下的评论随文章级联删除，因此不能删文章），
+	// 作者改为匿名化后的用户记录
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserToken{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RefreshToken{}).Error; err != nil {
+			return err
+		}
+
+		// 用户名和邮箱改为占位值以释放唯一索引，空密码哈希无法通过校验
+		return tx.Model(user).Updates(map[string]interface{}{
+			"username":          fmt.Sprintf("%s%d", models.DeletedUsernamePrefix, user.ID),
+			"email":             fmt.Sprintf("%s%d@deleted.invalid", models.DeletedUsernamePrefix, user.ID),
+			"password":          "",
+			"role":              models.RoleReader,
+			"email_verified_at": nil,
+			"display_name":      models.DeletedUserDisplayName,
+			"bio":               "",
+			"avatar_url":        "",
+			"deleted_at":        at,
+		}).Error
+	})
+}
+
+// revokeRefreshTokens 吊销用户所有未吊销的刷新令牌，使其在其他设备上的登录失效
+func revokeRefreshTokens(tx *gorm.DB, userID uint, at time.Time) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", at).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/models"
+)
+
+func TestAnonymizeKeepsContent(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+	alice := createTestUser(t, db, "alice")
+	bob := createTestUser(t, db, "bob")
+
+	posts := NewPostRepository(db)
+	comments := NewCommentRepository(db)
+	post := &models.Post{Title: "Alice's post", Content: "c", Slug: "alices-post", UserID: alice.ID}
+	if err := posts.Create(ctx, post, nil); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []*models.Comment{
+		{Content: "from bob", Status: models.CommentStatusApproved, UserID: bob.ID, PostID: post.ID},
+		{Content: "from alice", Status: models.CommentStatusApproved, UserID: alice.ID, PostID: post.ID},
+	} {
+		if err := comments.Create(ctx, c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&models.RefreshToken{UserID: alice.ID, FamilyID: "f", TokenHash: "h", ExpiresAt: time.Now().Add(time.Hour)}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := NewUserRepository(db).Anonymize(ctx, alice, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	detail, err := posts.FindDetail(ctx, post.ID)
+	if err != nil {
+		t.Fatalf("post deleted with its author: %v", err)
+	}
+	if len(detail.Comments) != 2 {
+		t.Errorf("post has %d comments after anonymize, want 2 (other users' comments must survive)", len(detail.Comments))
+	}
+	if detail.User.Username != fmt.Sprintf("deleted-%d", alice.ID) || detail.User.DisplayName != models.DeletedUserDisplayName {
+		t.Errorf("post author = %+v, want anonymized profile", detail.User)
+	}
+
+	var user models.User
+	if err := db.First(&user, alice.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !user.IsDeleted() || user.Email == "alice@example.com" || user.Password != "" || user.IsVerified() {
+		t.Errorf("user not anonymized: %+v", user)
+	}
+	var tokens int64
+	db.Model(&models.RefreshToken{}).Where("user_id = ?", alice.ID).Count(&tokens)
+	if tokens != 0 {
+		t.Errorf("%d refresh tokens left", tokens)
+	}
+
+	// 原用户名和邮箱可以重新注册
+	if err := NewUserRepository(db).Create(ctx, &models.User{Username: "alice", Email: "alice@example.com", Password: "x"}); err != nil {
+		t.Errorf("re-register released username: %v", err)
+	}
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"golang_task4_blog_system/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserTokenRepository 邮箱验证和密码重置令牌的数据访问接口。
+// 令牌只能使用一次，Consume 系列方法在令牌已被使用时返回 ErrNotFound
+type UserTokenRepository interface {
+	// Create 保存新令牌，同时作废该用户同一用途的其他未使用令牌
+	Create(ctx context.Context, token *models.UserToken) error
+	FindByHash(ctx context.Context, hash, purpose string) (*models.UserToken, error)
+	// ConsumeEmailVerification 使用验证令牌，把令牌中的邮箱设为用户已验证的邮箱
+	ConsumeEmailVerification(ctx context.Context, token *models.UserToken, at time.Time) error
+	// ConsumePasswordReset 使用重置令牌修改密码，并吊销该用户的全部刷新令牌
+	ConsumePasswordReset(ctx context.Context, token *models.UserToken, passwordHash string, at time.Time) error
+}
+
+type userTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewUserTokenRepository 创建基于 GORM 的一次性令牌仓储
+func NewUserTokenRepository(db *gorm.DB) UserTokenRepository {
+	return &userTokenRepository{db: db}
+}
+
+func (r *userTokenRepository) Create(ctx context.Context, token *models.UserToken) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.UserToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return translate(tx.Create(token).Error)
+	})
+}
+
+func (r *userTokenRepository) FindByHash(ctx context.Context, hash, purpose string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.WithContext(ctx).Where("token_hash = ? AND purpose = ?", hash, purpose).First(&token).Error
+	if err != nil {
+		return nil, translate(err)
+	}
+	return &token, nil
+}
+
+func (r *userTokenRepository) ConsumeEmailVerification(ctx context.Context, token *models.UserToken, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := consume(tx, token, at); err != nil {
+			return err
+		}
+		return translate(tx.Model(&models.User{ID: token.UserID}).Updates(map[string]interface{}{
+			"email":             token.Email,
+			"email_verified_at": at,
+		}).Error)
+	})
+}
+
+func (r *userTokenRepository) ConsumePasswordReset(ctx context.Context, token *models.UserToken, passwordHash string, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := consume(tx, token, at); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{ID: token.UserID}).Update("password", passwordHash).Error; err != nil {
+			return err
+		}
+		return revokeRefreshTokens(tx, token.UserID, at)
+	})
+}
+
+// consume 标记令牌已使用，条件更新防止同一令牌被并发使用两次
+func consume(tx *gorm.DB, token *models.UserToken, at time.Time) error {
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND used_at IS NULL", token.ID).
+		Update("used_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected != 1 {
+		return ErrNotFound
+	}
+	token.UsedAt = &at
+	return nil
+}
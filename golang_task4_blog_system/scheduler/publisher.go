@@ -0,0 +1,38 @@
+package scheduler
+
+import (
+	"context"
+	"golang_task4_blog_system/repository"
+	"log/slog"
+	"time"
+)
+
+// StartPostPublisher 启动定时发布任务，每隔 interval 将发布时间已到的定时文章改为已发布。
+// ctx 取消后任务退出，返回的通道在任务退出时关闭
+func StartPostPublisher(ctx context.Context, posts repository.PostRepository, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if n, err := posts.PublishDue(ctx, time.Now()); err != nil {
+				slog.ErrorContext(ctx, "failed to publish scheduled posts", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "published scheduled posts", "count", n)
+			}
+
+			select {
+			case <-ctx.Done():
+				slog.Info("post publisher stopped")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	return done
+}
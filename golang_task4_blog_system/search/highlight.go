@@ -0,0 +1,117 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	markOpen  = "<mark>"
+	markClose = "</mark>"
+	ellipsis  = "…"
+)
+
+type span struct {
+	Start int
+	End   int
+}
+
+// matchSpans 找出 text 中命中查询词的位置，重叠或相邻的片段合并
+func matchSpans(text string, terms []string) []span {
+	want := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		want[t] = true
+	}
+
+	var spans []span
+	for _, t := range tokenize(text) {
+		if !want[t.Term] {
+			continue
+		}
+		if n := len(spans); n > 0 && t.Start <= spans[n-1].End {
+			if t.End > spans[n-1].End {
+				spans[n-1].End = t.End
+			}
+			continue
+		}
+		spans = append(spans, span{t.Start, t.End})
+	}
+	return spans
+}
+
+// render 输出 text[start:end]，命中部分用 <mark> 包裹，其余内容做 HTML 转义
+func render(text string, start, end int, spans []span) string {
+	var b strings.Builder
+	pos := start
+	for _, s := range spans {
+		if s.End <= start || s.Start >= end {
+			continue
+		}
+		from, to := max(s.Start, start), min(s.End, end)
+		b.WriteString(html.EscapeString(text[pos:from]))
+		b.WriteString(markOpen)
+		b.WriteString(html.EscapeString(text[from:to]))
+		b.WriteString(markClose)
+		pos = to
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	return b.String()
+}
+
+// Highlight 高亮 text 中的查询词
+func Highlight(text, query string) string {
+	return render(text, 0, len(text), matchSpans(text, queryTerms(query)))
+}
+
+// Snippet 截取 text 中命中查询词最密集的一段（约 maxRunes 个字符）并高亮，
+// 没有命中时返回开头部分
+func Snippet(text, query string, maxRunes int) string {
+	spans := matchSpans(text, queryTerms(query))
+
+	start := 0
+	if len(spans) > 0 {
+		// 选出窗口内命中数最多的起点，并在命中前保留少量上下文
+		best, bestCount := 0, 0
+		for i := range spans {
+			windowEnd := advance(text, spans[i].Start, maxRunes)
+			count := 0
+			for j := i; j < len(spans) && spans[j].Start < windowEnd; j++ {
+				count++
+			}
+			if count > bestCount {
+				best, bestCount = i, count
+			}
+		}
+		start = retreat(text, spans[best].Start, maxRunes/4)
+	}
+	end := advance(text, start, maxRunes)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(ellipsis)
+	}
+	b.WriteString(render(text, start, end, spans))
+	if end < len(text) {
+		b.WriteString(ellipsis)
+	}
+	return b.String()
+}
+
+// advance 从字节位置 pos 向后移动 n 个字符
+func advance(text string, pos, n int) int {
+	for ; n > 0 && pos < len(text); n-- {
+		_, size := utf8.DecodeRuneInString(text[pos:])
+		pos += size
+	}
+	return pos
+}
+
+// retreat 从字节位置 pos 向前移动 n 个字符
+func retreat(text string, pos, n int) int {
+	for ; n > 0 && pos > 0; n-- {
+		_, size := utf8.DecodeLastRuneInString(text[:pos])
+		pos -= size
+	}
+	return pos
+}
@@ -0,0 +1,86 @@
+package search
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		query string
+		want  string
+	}{
+		{"word", "Learn Go today", "go", "Learn <mark>Go</mark> today"},
+		{"no partial word", "Gopher", "go", "Gopher"},
+		{"escapes html", "<b>Go</b> & more", "go", "&lt;b&gt;<mark>Go</mark>&lt;/b&gt; &amp; more"},
+		{"cjk bigrams merged", "学习数据库索引", "数据库", "学习<mark>数据库</mark>索引"},
+		{"adjacent terms merged", "hello world", "world hello", "<mark>hello</mark> <mark>world</mark>"},
+		{"no match", "nothing here", "go", "nothing here"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Highlight(tt.text, tt.query); got != tt.want {
+				t.Errorf("Highlight(%q, %q) = %q, want %q", tt.text, tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+// plain 去掉高亮标记和省略号，还原片段原文
+func plain(s string) string {
+	return strings.NewReplacer(markOpen, "", markClose, "", ellipsis, "").Replace(s)
+}
+
+func TestSnippetBoundaries(t *testing.T) {
+	long := strings.Repeat("filler ", 40) + "target word here " + strings.Repeat("tail ", 40)
+	cjk := strings.Repeat("无关内容", 30) + "目标关键字" + strings.Repeat("其他文字", 30)
+
+	tests := []struct {
+		name          string
+		text          string
+		query         string
+		maxRunes      int
+		wantPrefix    bool // 以省略号开头
+		wantSuffix    bool // 以省略号结尾
+		wantContained string
+	}{
+		{"short text untouched", "short target", "target", 50, false, false, "<mark>target</mark>"},
+		{"match in middle", long, "target", 40, true, true, "<mark>target</mark>"},
+		{"match at start", "target " + strings.Repeat("x ", 100), "target", 20, false, true, "<mark>target</mark>"},
+		{"match at end", strings.Repeat("x ", 100) + "target", "target", 20, true, false, "<mark>target</mark>"},
+		{"no match returns head", long, "absent", 30, false, true, "filler"},
+		{"cjk", cjk, "关键字", 20, true, true, "<mark>关键字</mark>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Snippet(tt.text, tt.query, tt.maxRunes)
+			if strings.HasPrefix(got, ellipsis) != tt.wantPrefix {
+				t.Errorf("Snippet = %q, prefix ellipsis want %v", got, tt.wantPrefix)
+			}
+			if strings.HasSuffix(got, ellipsis) != tt.wantSuffix {
+				t.Errorf("Snippet = %q, suffix ellipsis want %v", got, tt.wantSuffix)
+			}
+			if !strings.Contains(got, tt.wantContained) {
+				t.Errorf("Snippet = %q, want it to contain %q", got, tt.wantContained)
+			}
+			body := plain(got)
+			if n := utf8.RuneCountInString(body); n > tt.maxRunes {
+				t.Errorf("Snippet has %d runes, want at most %d", n, tt.maxRunes)
+			}
+			if !utf8.ValidString(body) || !strings.Contains(tt.text, body) {
+				t.Errorf("Snippet %q is not a valid substring of the text", body)
+			}
+		})
+	}
+}
+
+func TestSnippetPicksDensestWindow(t *testing.T) {
+	text := "go " + strings.Repeat("padding ", 30) + "go go go " + strings.Repeat("end ", 30)
+	got := Snippet(text, "go", 20)
+	if n := strings.Count(got, markOpen); n != 3 {
+		t.Errorf("Snippet = %q, want the window with 3 matches", got)
+	}
+}
@@ -0,0 +1,188 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// 文档类型
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+// BM25 参数，标题中的词按 titleBoost 倍计入词频
+const (
+	bm25K1     = 1.2
+	bm25B      = 0.75
+	titleBoost = 3
+)
+
+// Document 被索引的文档
+type Document struct {
+	Kind   string
+	ID     uint
+	PostID uint // 评论所属文章，文章为自身ID
+	Title  string
+	Body   string
+}
+
+// Hit 搜索命中结果
+type Hit struct {
+	Document
+	Score float64
+}
+
+type docKey struct {
+	Kind string
+	ID   uint
+}
+
+type entry struct {
+	doc    Document
+	length int // 按 titleBoost 加权后的词数
+	terms  map[string]int
+}
+
+// Index 内存倒排索引，并发安全
+type Index struct {
+	mu          sync.RWMutex
+	docs        map[docKey]*entry
+	postings    map[string]map[docKey]int // 词 -> 文档 -> 加权词频
+	totalLength int
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[docKey]*entry),
+		postings: make(map[string]map[docKey]int),
+	}
+}
+
+// Default 应用使用的全局索引，由模型钩子维护
+var Default = NewIndex()
+
+// Add 添加或替换文档
+func (idx *Index) Add(doc Document) {
+	terms := make(map[string]int)
+	length := 0
+	for _, t := range tokenize(doc.Title) {
+		terms[t.Term] += titleBoost
+		length += titleBoost
+	}
+	for _, t := range tokenize(doc.Body) {
+		terms[t.Term]++
+		length++
+	}
+
+	key := docKey{doc.Kind, doc.ID}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(key)
+	idx.docs[key] = &entry{doc: doc, length: length, terms: terms}
+	idx.totalLength += length
+	for term, tf := range terms {
+		if idx.postings[term] == nil {
+			idx.postings[term] = make(map[docKey]int)
+		}
+		idx.postings[term][key] = tf
+	}
+}
+
+// Remove 删除文档，不存在时忽略
+func (idx *Index) Remove(kind string, id uint) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.removeLocked(docKey{kind, id})
+}
+
+// Reset 清空索引
+func (idx *Index) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.docs = make(map[docKey]*entry)
+	idx.postings = make(map[string]map[docKey]int)
+	idx.totalLength = 0
+}
+
+// Len 文档数量
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+func (idx *Index) removeLocked(key docKey) {
+	e, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+	for term := range e.terms {
+		delete(idx.postings[term], key)
+		if len(idx.postings[term]) == 0 {
+			delete(idx.postings, term)
+		}
+	}
+	idx.totalLength -= e.length
+	delete(idx.docs, key)
+}
+
+// Search 按 BM25 计算相关度，返回按得分降序排列的全部命中；
+// kinds 为空时搜索所有类型
+func (idx *Index) Search(query string, kinds ...string) []Hit {
+	terms := queryTerms(query)
+	if len(terms) == 0 {
+		return nil
+	}
+
+	allowed := make(map[string]bool, len(kinds))
+	for _, k := range kinds {
+		allowed[k] = true
+	}
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	n := float64(len(idx.docs))
+	if n == 0 {
+		return nil
+	}
+	avgLen := float64(idx.totalLength) / n
+
+	scores := make(map[docKey]float64)
+	for _, term := range terms {
+		postings := idx.postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for key, tf := range postings {
+			if len(allowed) > 0 && !allowed[key.Kind] {
+				continue
+			}
+			docLen := float64(idx.docs[key].length)
+			f := float64(tf)
+			scores[key] += idf * f * (bm25K1 + 1) / (f + bm25K1*(1-bm25B+bm25B*docLen/avgLen))
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for key, score := range scores {
+		hits = append(hits, Hit{Document: idx.docs[key].doc, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Kind != hits[j].Kind {
+			return hits[i].Kind > hits[j].Kind // 同分时文章排在评论前
+		}
+		return hits[i].ID > hits[j].ID
+	})
+	return hits
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"testing"
+)
+
+func hitIDs(hits []Hit) []uint {
+	ids := make([]uint, len(hits))
+	for i, h := range hits {
+		ids[i] = h.ID
+	}
+	return ids
+}
+
+func TestSearchRanking(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{Kind: KindPost, ID: 1, PostID: 1, Title: "Cooking pasta", Body: "Boil water and add salt. Golang is not involved."})
+	idx.Add(Document{Kind: KindPost, ID: 2, PostID: 2, Title: "Golang generics", Body: "Generics arrived in golang 1.18."})
+	idx.Add(Document{Kind: KindPost, ID: 3, PostID: 3, Title: "Travel notes", Body: "A long trip across mountains, rivers and many small towns with golang."})
+	idx.Add(Document{Kind: KindComment, ID: 4, PostID: 1, Body: "Nice recipe"})
+
+	tests := []struct {
+		name  string
+		query string
+		kinds []string
+		want  []uint
+	}{
+		// 标题命中加权，且词频更高的文档排在前面
+		{"title boost", "golang", nil, []uint{2, 1, 3}},
+		{"rare term wins", "golang generics", nil, []uint{2, 1, 3}},
+		{"no match", "kubernetes", nil, []uint{}},
+		{"kind filter", "recipe pasta", []string{KindComment}, []uint{4}},
+		{"all kinds, short comment first", "recipe pasta", nil, []uint{4, 1}},
+		{"case insensitive", "GOLANG", []string{KindPost}, []uint{2, 1, 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := hitIDs(idx.Search(tt.query, tt.kinds...))
+			if len(got) != len(tt.want) {
+				t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSearchShorterDocumentScoresHigher(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{Kind: KindPost, ID: 1, Body: "golang"})
+	idx.Add(Document{Kind: KindPost, ID: 2, Body: "golang with a lot of unrelated words padding the body out"})
+	idx.Add(Document{Kind: KindPost, ID: 3, Body: "something else"})
+
+	hits := idx.Search("golang")
+	if len(hits) != 2 || hits[0].ID != 1 || hits[0].Score <= hits[1].Score {
+		t.Fatalf("hits = %+v, want shorter document first", hits)
+	}
+}
+
+func TestSearchTieBreak(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{Kind: KindComment, ID: 5, Body: "same text"})
+	idx.Add(Document{Kind: KindPost, ID: 1, Body: "same text"})
+	idx.Add(Document{Kind: KindPost, ID: 2, Body: "same text"})
+
+	hits := idx.Search("same")
+	if len(hits) != 3 {
+		t.Fatalf("hits = %+v", hits)
+	}
+	// 同分时文章排在评论前，同类型按ID降序
+	if hits[0].Kind != KindPost || hits[0].ID != 2 || hits[1].ID != 1 || hits[2].Kind != KindComment {
+		t.Errorf("hits = %+v", hits)
+	}
+}
+
+func TestIndexReplaceAndRemove(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{Kind: KindPost, ID: 1, Title: "old title"})
+	idx.Add(Document{Kind: KindPost, ID: 1, Title: "new title"})
+	if idx.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", idx.Len())
+	}
+	if hits := idx.Search("old"); len(hits) != 0 {
+		t.Errorf("replaced document still matches old title: %+v", hits)
+	}
+	if hits := idx.Search("new"); len(hits) != 1 {
+		t.Errorf("Search(new) = %+v", hits)
+	}
+
+	idx.Remove(KindPost, 1)
+	idx.Remove(KindPost, 99)
+	if idx.Len() != 0 || idx.totalLength != 0 || len(idx.postings) != 0 {
+		t.Errorf("index not empty after remove: len=%d total=%d postings=%d", idx.Len(), idx.totalLength, len(idx.postings))
+	}
+}
+
+func TestSearchCJK(t *testing.T) {
+	idx := NewIndex()
+	idx.Add(Document{Kind: KindPost, ID: 1, Title: "数据库索引优化"})
+	idx.Add(Document{Kind: KindPost, ID: 2, Title: "数字货币"})
+
+	if got := hitIDs(idx.Search("索引")); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Search(索引) = %v, want [1]", got)
+	}
+	// "数据" 与 "数字" 不共享二元组
+	if got := hitIDs(idx.Search("数据")); len(got) != 1 || got[0] != 1 {
+		t.Errorf("Search(数据) = %v, want [1]", got)
+	}
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// token 分词结果，Start/End 为在原文中的字节位置
+type token struct {
+	Term  string
+	Start int
+	End   int
+}
+
+// isCJK 中日韩文字没有空格分词，按相邻两字切分
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
+
+// tokenize 分词：拉丁等字母文字按单词切分并转小写，中日韩文字按二元组切分
+// （单个汉字成段时作为一个词）
+func tokenize(text string) []token {
+	var tokens []token
+
+	wordStart := -1
+	var cjk []int // 当前中日韩连续片段中每个字的起始位置
+
+	flushWord := func(end int) {
+		if wordStart >= 0 {
+			tokens = append(tokens, token{
+				Term:  strings.ToLower(text[wordStart:end]),
+				Start: wordStart,
+				End:   end,
+			})
+			wordStart = -1
+		}
+	}
+	flushCJK := func(end int) {
+		switch len(cjk) {
+		case 0:
+			return
+		case 1:
+			tokens = append(tokens, token{Term: text[cjk[0]:end], Start: cjk[0], End: end})
+		default:
+			for i := 0; i < len(cjk)-1; i++ {
+				stop := end
+				if i+2 < len(cjk) {
+					stop = cjk[i+2]
+				}
+				tokens = append(tokens, token{Term: text[cjk[i]:stop], Start: cjk[i], End: stop})
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for i, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord(i)
+			cjk = append(cjk, i)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r):
+			flushCJK(i)
+			if wordStart < 0 {
+				wordStart = i
+			}
+		default:
+			flushWord(i)
+			flushCJK(i)
+		}
+	}
+	flushWord(len(text))
+	flushCJK(len(text))
+
+	return tokens
+}
+
+// queryTerms 查询语句分词并去重
+func queryTerms(q string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, t := range tokenize(q) {
+		if !seen[t.Term] {
+			seen[t.Term] = true
+			terms = append(terms, t.Term)
+		}
+	}
+	return terms
+}
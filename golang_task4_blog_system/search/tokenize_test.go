@@ -0,0 +1,53 @@
+package search
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"latin lowercased", "Hello, GORM World", []string{"hello", "gorm", "world"}},
+		{"digits kept", "Go 1.22 release", []string{"go", "1", "22", "release"}},
+		{"chinese bigrams", "数据库", []string{"数据", "据库"}},
+		{"single han", "用 Go 写", []string{"用", "go", "写"}},
+		{"mixed script boundary", "Go语言", []string{"go", "语言"}},
+		{"punctuation splits cjk", "你好，世界", []string{"你好", "世界"}},
+		{"japanese kana", "ひらがな", []string{"ひら", "らが", "がな"}},
+		{"korean", "한국어", []string{"한국", "국어"}},
+		{"empty", "  ，。 ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, tok := range tokenize(tt.text) {
+				got = append(got, tok.Term)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tokenize(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenizeOffsets(t *testing.T) {
+	text := "Go语言 入门"
+	for _, tok := range tokenize(text) {
+		if strings.ToLower(text[tok.Start:tok.End]) != tok.Term {
+			t.Errorf("token %q has offsets [%d:%d] = %q", tok.Term, tok.Start, tok.End, text[tok.Start:tok.End])
+		}
+	}
+}
+
+func TestQueryTermsDeduplicates(t *testing.T) {
+	got := queryTerms("Go go GO 语言语言")
+	want := []string{"go", "语言", "言语"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("queryTerms = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/mail"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// AccountOptions 邮箱验证和密码重置的参数。
+// 邮件中的链接为 BaseURL + "/verify-email?token=..." 和 BaseURL + "/reset-password?token=..."，
+// 由前端页面取出令牌后调用对应接口
+type AccountOptions struct {
+	BaseURL          string
+	VerificationTTL  time.Duration
+	PasswordResetTTL time.Duration
+}
+
+// AccountService 邮箱验证和找回密码。令牌随机生成并通过邮件发送，数据库中只保存哈希值，
+// 过期或使用后失效，同一用途的新令牌签发后旧令牌作废
+type AccountService interface {
+	// SendVerification 向用户当前邮箱发送验证邮件，邮箱已验证时返回冲突错误
+	SendVerification(ctx context.Context, user *models.User) error
+	// ChangeEmail 向新邮箱发送验证邮件，验证通过后才替换用户的邮箱，此前仍使用原邮箱。
+	// 调用方负责在此之前确认用户身份
+	ChangeEmail(ctx context.Context, actor *models.User, email string) error
+	// VerifyEmail 使用验证令牌完成验证，返回验证后的用户
+	VerifyEmail(ctx context.Context, token string) (*models.User, error)
+	// RequestPasswordReset 向邮箱对应的用户发送重置邮件。
+	// 邮箱未注册时同样返回成功，避免通过该接口探测已注册的邮箱
+	RequestPasswordReset(ctx context.Context, email string) error
+	// ResetPassword 使用重置令牌设置新密码，并让该用户所有已登录的会话失效
+	ResetPassword(ctx context.Context, token, password string) error
+}
+
+type accountService struct {
+	users  repository.UserRepository
+	tokens repository.UserTokenRepository
+	mailer mail.Mailer
+	opts   AccountOptions
+}
+
+// NewAccountService 创建账户服务
+func NewAccountService(users repository.UserRepository, tokens repository.UserTokenRepository, mailer mail.Mailer, opts AccountOptions) AccountService {
+	opts.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+	return &accountService{users: users, tokens: tokens, mailer: mailer, opts: opts}
+}
+
+func (s *accountService) SendVerification(ctx context.Context, user *models.User) error {
+	if user.IsVerified() {
+		return apperr.Conflict(apperr.CodeEmailAlreadyVerified, "邮箱已验证")
+	}
+
+	return s.sendVerification(ctx, user, user.Email)
+}
+
+func (s *accountService) ChangeEmail(ctx context.Context, actor *models.User, email string) error {
+	if email == actor.Email && actor.IsVerified() {
+		return apperr.Validation(apperr.CodeEmailUnchanged, "新邮箱与当前邮箱相同").
+			WithFields(apperr.Field("email", "unchanged", "新邮箱与当前邮箱相同"))
+	}
+
+	existing, err := s.users.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return apperr.Internal("查找用户失败", err)
+	}
+	if existing != nil && existing.ID != actor.ID {
+		return apperr.Conflict(apperr.CodeUserExists, "邮箱已被注册").
+			WithFields(apperr.Field("email", "taken", "邮箱已被注册"))
+	}
+
+	return s.sendVerification(ctx, actor, email)
+}
+
+// sendVerification 签发验证令牌并发送到 email，验证通过后该邮箱成为用户已验证的邮箱
+func (s *accountService) sendVerification(ctx context.Context, user *models.User, email string) error {
+	token, err := s.issue(ctx, user, email, models.TokenPurposeEmailVerification, s.opts.VerificationTTL)
+	if err != nil {
+		return apperr.Internal("生成验证令牌失败", err)
+	}
+
+	msg := mail.Message{
+		To:      email,
+		Subject: "请验证你的邮箱",
+		Body: fmt.Sprintf("%s，你好：\n\n请打开以下链接验证邮箱，链接%s内有效：\n\n%s\n\n如果这不是你的操作，请忽略本邮件。\n",
+			user.Username, formatTTL(s.opts.VerificationTTL), s.link("/verify-email", token)),
+	}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return apperr.Internal("发送验证邮件失败", err)
+	}
+	return nil
+}
+
+func (s *accountService) VerifyEmail(ctx context.Context, token string) (*models.User, error) {
+	invalid := apperr.Validation(apperr.CodeVerificationTokenInvalid, "验证链接无效或已过期")
+
+	record, err := s.lookup(ctx, token, models.TokenPurposeEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, invalid
+	}
+
+	if err := s.tokens.ConsumeEmailVerification(ctx, record, time.Now()); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			return nil, invalid
+		case errors.Is(err, repository.ErrDuplicated):
+			return nil, apperr.Conflict(apperr.CodeUserExists, "邮箱已被其他用户使用")
+		}
+		return nil, apperr.Internal("验证邮箱失败", err)
+	}
+
+	user, err := s.users.FindByID(ctx, record.UserID)
+	if err != nil {
+		return nil, apperr.Internal("查找用户失败", err)
+	}
+	return user, nil
+}
+
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.users.FindByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return apperr.Internal("查找用户失败", err)
+	}
+
+	token, err := s.issue(ctx, user, user.Email, models.TokenPurposePasswordReset, s.opts.PasswordResetTTL)
+	if err != nil {
+		return apperr.Internal("生成重置令牌失败", err)
+	}
+
+	msg := mail.Message{
+		To:      user.Email,
+		Subject: "重置密码",
+		Body: fmt.Sprintf("%s，你好：\n\n请打开以下链接设置新密码，链接%s内有效且只能使用一次：\n\n%s\n\n如果这不是你的操作，请忽略本邮件，你的密码不会改变。\n",
+			user.Username, formatTTL(s.opts.PasswordResetTTL), s.link("/reset-password", token)),
+	}
+	// 发送失败只记录日志，响应与邮箱未注册时一致
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		slog.ErrorContext(ctx, "failed to send password reset mail", "user_id", user.ID, "error", err)
+	}
+	return nil
+}
+
+func (s *accountService) ResetPassword(ctx context.Context, token, password string) error {
+	invalid := apperr.Validation(apperr.CodeResetTokenInvalid, "重置链接无效或已过期")
+
+	record, err := s.lookup(ctx, token, models.TokenPurposePasswordReset)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return invalid
+	}
+
+	user := models.User{Password: password}
+	if err := user.HashPassword(); err != nil {
+		return apperr.Internal("密码加密失败", err)
+	}
+
+	if err := s.tokens.ConsumePasswordReset(ctx, record, user.Password, time.Now()); err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return invalid
+		}
+		return apperr.Internal("重置密码失败", err)
+	}
+	return nil
+}
+
+// issue 为用户签发一次性令牌，email 为令牌对应的邮箱，返回明文令牌
+func (s *accountService) issue(ctx context.Context, user *models.User, email, purpose string, ttl time.Duration) (string, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.tokens.Create(ctx, &models.UserToken{
+		UserID:    user.ID,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     email,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// lookup 查找可用的令牌，令牌不存在、已使用或已过期时返回 nil
+func (s *accountService) lookup(ctx context.Context, token, purpose string) (*models.UserToken, error) {
+	if token == "" {
+		return nil, nil
+	}
+	record, err := s.tokens.FindByHash(ctx, hashToken(token), purpose)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, apperr.Internal("查找令牌失败", err)
+	}
+	if !record.IsUsable(time.Now()) {
+		return nil, nil
+	}
+	return record, nil
+}
+
+func (s *accountService) link(path, token string) string {
+	return s.opts.BaseURL + path + "?" + url.Values{"token": {token}}.Encode()
+}
+
+// formatTTL 以邮件中易读的方式显示有效期
+func formatTTL(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", d/time.Hour)
+	}
+	return fmt.Sprintf("%d 分钟", d/time.Minute)
+}
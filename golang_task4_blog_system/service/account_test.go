@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/mail"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+
+	"gorm.io/gorm"
+)
+
+// mailbox 记录发出的邮件，代替真实的发送方式
+type mailbox struct {
+	mu       sync.Mutex
+	messages []mail.Message
+}
+
+func (m *mailbox) Send(ctx context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+var tokenPattern = regexp.MustCompile(`\?token=([A-Za-z0-9_-]+)`)
+
+// lastToken 取出最后一封邮件中链接的令牌
+func (m *mailbox) lastToken(t *testing.T) string {
+	t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.messages) == 0 {
+		t.Fatal("no mail sent")
+	}
+	match := tokenPattern.FindStringSubmatch(m.messages[len(m.messages)-1].Body)
+	if match == nil {
+		t.Fatalf("no token in %q", m.messages[len(m.messages)-1].Body)
+	}
+	return match[1]
+}
+
+// accountFixture 基于 SQLite 内存数据库的账户服务和用户服务，alice 的邮箱未验证，密码为 secret123
+type accountFixture struct {
+	db       *gorm.DB
+	accounts AccountService
+	users    UserService
+	mails    *mailbox
+	alice    *models.User
+}
+
+func newAccountFixture(t *testing.T) *accountFixture {
+	t.Helper()
+	db := newTestDB(t)
+	userRepo := repository.NewUserRepository(db)
+	f := &accountFixture{db: db, mails: &mailbox{}}
+	f.accounts = NewAccountService(userRepo, repository.NewUserTokenRepository(db), f.mails, AccountOptions{
+		BaseURL:          "http://blog.test/",
+		VerificationTTL:  time.Hour,
+		PasswordResetTTL: time.Hour,
+	})
+	f.users = NewUserService(userRepo, repository.NewRefreshTokenRepository(db), nil)
+
+	f.alice = &models.User{Username: "alice", Email: "alice@example.com", Password: "secret123", Role: models.RoleAuthor}
+	if err := f.alice.HashPassword(); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(f.alice).Error; err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// reload 重新读取 alice
+func (f *accountFixture) reload(t *testing.T) *models.User {
+	t.Helper()
+	user, err := f.users.GetByID(context.Background(), f.alice.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// expireTokens 让 alice 某用途的令牌全部过期
+func (f *accountFixture) expireTokens(t *testing.T, purpose string) {
+	t.Helper()
+	if err := f.db.Model(&models.UserToken{}).
+		Where("user_id = ? AND purpose = ?", f.alice.ID, purpose).
+		Update("expires_at", time.Now().Add(-time.Minute)).Error; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	isInvalid := func(err error) bool {
+		var e *apperr.Error
+		return errors.As(err, &e) && e.Code == apperr.CodeVerificationTokenInvalid
+	}
+
+	t.Run("single use", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.SendVerification(ctx, f.alice); err != nil {
+			t.Fatal(err)
+		}
+		token := f.mails.lastToken(t)
+
+		user, err := f.accounts.VerifyEmail(ctx, token)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !user.IsVerified() || !f.reload(t).IsVerified() {
+			t.Error("user not verified")
+		}
+		if _, err := f.accounts.VerifyEmail(ctx, token); !isInvalid(err) {
+			t.Errorf("second use: err = %v, want invalid", err)
+		}
+		if err := f.accounts.SendVerification(ctx, f.reload(t)); !apperr.IsKind(err, apperr.KindConflict) {
+			t.Errorf("resend after verification: err = %v, want conflict", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.SendVerification(ctx, f.alice); err != nil {
+			t.Fatal(err)
+		}
+		f.expireTokens(t, models.TokenPurposeEmailVerification)
+		if _, err := f.accounts.VerifyEmail(ctx, f.mails.lastToken(t)); !isInvalid(err) {
+			t.Errorf("err = %v, want invalid", err)
+		}
+		if f.reload(t).IsVerified() {
+			t.Error("expired token verified the user")
+		}
+	})
+
+	t.Run("new token replaces old", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.SendVerification(ctx, f.alice); err != nil {
+			t.Fatal(err)
+		}
+		old := f.mails.lastToken(t)
+		if err := f.accounts.SendVerification(ctx, f.alice); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := f.accounts.VerifyEmail(ctx, old); !isInvalid(err) {
+			t.Errorf("old token: err = %v, want invalid", err)
+		}
+		if _, err := f.accounts.VerifyEmail(ctx, f.mails.lastToken(t)); err != nil {
+			t.Errorf("new token: %v", err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		f := newAccountFixture(t)
+		for _, token := range []string{"", "not-a-token"} {
+			if _, err := f.accounts.VerifyEmail(ctx, token); !isInvalid(err) {
+				t.Errorf("VerifyEmail(%q): err = %v, want invalid", token, err)
+			}
+		}
+	})
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+	isInvalid := func(err error) bool {
+		var e *apperr.Error
+		return errors.As(err, &e) && e.Code == apperr.CodeResetTokenInvalid
+	}
+
+	t.Run("resets password and revokes sessions", func(t *testing.T) {
+		f := newAccountFixture(t)
+		refresh, err := f.users.IssueRefreshToken(ctx, f.alice)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := f.accounts.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		token := f.mails.lastToken(t)
+
+		if err := f.accounts.ResetPassword(ctx, token, "newsecret456"); err != nil {
+			t.Fatal(err)
+		}
+		user := f.reload(t)
+		if !user.CheckPassword("newsecret456") || user.CheckPassword("secret123") {
+			t.Error("password not changed")
+		}
+		if _, _, err := f.users.RefreshToken(ctx, refresh); err == nil {
+			t.Error("refresh token still usable after password reset")
+		}
+		if err := f.accounts.ResetPassword(ctx, token, "another789"); !isInvalid(err) {
+			t.Errorf("second use: err = %v, want invalid", err)
+		}
+		if !f.reload(t).CheckPassword("newsecret456") {
+			t.Error("second use changed the password")
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		f.expireTokens(t, models.TokenPurposePasswordReset)
+		if err := f.accounts.ResetPassword(ctx, f.mails.lastToken(t), "newsecret456"); !isInvalid(err) {
+			t.Errorf("err = %v, want invalid", err)
+		}
+		if !f.reload(t).CheckPassword("secret123") {
+			t.Error("expired token changed the password")
+		}
+	})
+
+	t.Run("new token replaces old", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		old := f.mails.lastToken(t)
+		if err := f.accounts.RequestPasswordReset(ctx, "alice@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.accounts.ResetPassword(ctx, old, "newsecret456"); !isInvalid(err) {
+			t.Errorf("old token: err = %v, want invalid", err)
+		}
+		if err := f.accounts.ResetPassword(ctx, f.mails.lastToken(t), "newsecret456"); err != nil {
+			t.Errorf("new token: %v", err)
+		}
+	})
+
+	t.Run("verification token cannot reset", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.SendVerification(ctx, f.alice); err != nil {
+			t.Fatal(err)
+		}
+		if err := f.accounts.ResetPassword(ctx, f.mails.lastToken(t), "newsecret456"); !isInvalid(err) {
+			t.Errorf("err = %v, want invalid", err)
+		}
+	})
+
+	t.Run("unknown email sends nothing", func(t *testing.T) {
+		f := newAccountFixture(t)
+		if err := f.accounts.RequestPasswordReset(ctx, "nobody@example.com"); err != nil {
+			t.Fatal(err)
+		}
+		if len(f.mails.messages) != 0 {
+			t.Errorf("sent %d mails, want none", len(f.mails.messages))
+		}
+	})
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+// CategoryInput 创建和修改分类的参数。修改时空字段保持不变；
+// Description 和 ParentID 为 nil 时不修改，ParentID 为 0 表示顶级分类
+type CategoryInput struct {
+	Name        string
+	Slug        string // 未指定时由名称生成
+	Description *string
+	ParentID    *uint
+}
+
+// CategoryNode 分类树节点，PostCount 为直接属于该分类的已发布文章数，
+// TotalCount 包含所有子分类
+type CategoryNode struct {
+	models.Category
+	PostCount  int64           `json:"post_count"`
+	TotalCount int64           `json:"total_count"`
+	Children   []*CategoryNode `json:"children"`
+}
+
+func (s *postService) Categories(ctx context.Context) ([]*CategoryNode, error) {
+	categories, err := s.posts.ListCategories(ctx)
+	if err != nil {
+		return nil, apperr.Internal("获取分类失败", err)
+	}
+	counts, err := s.posts.CountPublishedByCategory(ctx)
+	if err != nil {
+		return nil, apperr.Internal("获取分类失败", err)
+	}
+
+	nodes := make(map[uint]*CategoryNode, len(categories))
+	for _, cat := range categories {
+		nodes[cat.ID] = &CategoryNode{Category: cat, PostCount: counts[cat.ID], Children: []*CategoryNode{}}
+	}
+
+	roots := []*CategoryNode{}
+	for _, cat := range categories {
+		node := nodes[cat.ID]
+		if parent, ok := nodes[derefID(cat.ParentID)]; ok {
+			parent.Children = append(parent.Children, node)
+		} else {
+			roots = append(roots, node)
+		}
+	}
+	for _, root := range roots {
+		sumCategoryCounts(root)
+	}
+	return roots, nil
+}
+
+func sumCategoryCounts(node *CategoryNode) int64 {
+	node.TotalCount = node.PostCount
+	for _, child := range node.Children {
+		node.TotalCount += sumCategoryCounts(child)
+	}
+	return node.TotalCount
+}
+
+func (s *postService) CreateCategory(ctx context.Context, input CategoryInput) (*models.Category, error) {
+	category := models.Category{Name: input.Name}
+	if input.Description != nil {
+		category.Description = *input.Description
+	}
+
+	// 上级分类必须存在，0 表示顶级分类
+	if parentID := derefID(input.ParentID); parentID != 0 {
+		if err := s.checkCategoryParent(ctx, 0, parentID); err != nil {
+			return nil, err
+		}
+		category.ParentID = input.ParentID
+	}
+
+	// slug 可由请求指定，否则取名称
+	slugSource := input.Name
+	if input.Slug != "" {
+		slugSource = input.Slug
+	}
+	category.Slug = models.Slugify(slugSource)
+	if err := s.checkCategorySlug(ctx, category.Slug, 0); err != nil {
+		return nil, err
+	}
+
+	if err := s.posts.CreateCategory(ctx, &category); err != nil {
+		if errors.Is(err, repository.ErrDuplicated) {
+			return nil, errCategorySlugTaken()
+		}
+		return nil, apperr.Internal("创建分类失败", err)
+	}
+	return &category, nil
+}
+
+func (s *postService) UpdateCategory(ctx context.Context, id uint, input CategoryInput) (*models.Category, error) {
+	category, err := s.posts.FindCategory(ctx, id)
+	if err != nil {
+		return nil, categoryLookupError(err, "查找分类失败")
+	}
+
+	// 构建更新数据
+	fields := make(map[string]interface{})
+	if input.Name != "" {
+		fields["name"] = input.Name
+	}
+	if input.Description != nil {
+		fields["description"] = *input.Description
+	}
+	if input.Slug != "" {
+		slug := models.Slugify(input.Slug)
+		if err := s.checkCategorySlug(ctx, slug, category.ID); err != nil {
+			return nil, err
+		}
+		fields["slug"] = slug
+	}
+	if input.ParentID != nil {
+		if *input.ParentID == 0 {
+			fields["parent_id"] = nil
+		} else {
+			if err := s.checkCategoryParent(ctx, category.ID, *input.ParentID); err != nil {
+				return nil, err
+			}
+			fields["parent_id"] = *input.ParentID
+		}
+	}
+
+	if err := s.posts.UpdateCategory(ctx, category, fields); err != nil {
+		if errors.Is(err, repository.ErrDuplicated) {
+			return nil, errCategorySlugTaken()
+		}
+		return nil, apperr.Internal("更新分类失败", err)
+	}
+	return category, nil
+}
+
+func (s *postService) DeleteCategory(ctx context.Context, id uint) error {
+	category, err := s.posts.FindCategory(ctx, id)
+	if err != nil {
+		return categoryLookupError(err, "查找分类失败")
+	}
+
+	if err := s.posts.DeleteCategory(ctx, category); err != nil {
+		return apperr.Internal("删除分类失败", err)
+	}
+	return nil
+}
+
+// checkCategorySlug 检查 slug 是否已被其他分类使用
+func (s *postService) checkCategorySlug(ctx context.Context, slug string, categoryID uint) error {
+	taken, err := s.posts.CategorySlugTaken(ctx, slug, categoryID)
+	if err != nil {
+		return apperr.Internal("检查分类失败", err)
+	}
+	if taken {
+		return errCategorySlugTaken()
+	}
+	return nil
+}
+
+// checkCategoryParent 检查 parentID 存在且不是 categoryID 自身或其子分类，创建分类时 categoryID 为 0
+func (s *postService) checkCategoryParent(ctx context.Context, categoryID, parentID uint) error {
+	for id := parentID; id != 0; {
+		if id == categoryID {
+			return apperr.Validation(apperr.CodeCategoryCycle, "不能把分类移动到自身或其子分类下")
+		}
+		parent, err := s.posts.FindCategory(ctx, id)
+		if err != nil {
+			if errors.Is(err, repository.ErrNotFound) {
+				return apperr.Validation(apperr.CodeInvalidParentCategory, "上级分类不存在").
+					WithFields(apperr.Field("parent_id", "not_found", "上级分类不存在"))
+			}
+			return apperr.Internal("检查分类失败", err)
+		}
+		id = derefID(parent.ParentID)
+	}
+	return nil
+}
+
+func errCategorySlugTaken() *apperr.Error {
+	return apperr.Conflict(apperr.CodeCategorySlugTaken, "分类 slug 已存在").
+		WithFields(apperr.Field("slug", "taken", "分类 slug 已存在"))
+}
+
+// categoryLookupError 把查找分类时的仓储错误转换为 404 或 500
+func categoryLookupError(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return apperr.NotFound(apperr.CodeCategoryNotFound, "分类不存在")
+	}
+	return apperr.Internal(message, err)
+}
+
+func derefID(id *uint) uint {
+	if id == nil {
+		return 0
+	}
+	return *id
+}
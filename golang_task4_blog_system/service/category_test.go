@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+)
+
+func TestCheckCategoryParent(t *testing.T) {
+	// 分类树：1 → 2 → 3，4 为另一棵树的根
+	parent := func(id uint) *uint { return &id }
+	s := &postService{posts: &fakePosts{categories: map[uint]*models.Category{
+		1: {ID: 1},
+		2: {ID: 2, ParentID: parent(1)},
+		3: {ID: 3, ParentID: parent(2)},
+		4: {ID: 4},
+	}}}
+
+	tests := []struct {
+		name       string
+		categoryID uint
+		parentID   uint
+		wantCode   string // 为空表示允许
+	}{
+		{"new category under leaf", 0, 3, ""},
+		{"move to another tree", 2, 4, ""},
+		{"move leaf up", 3, 1, ""},
+		{"move to itself", 2, 2, apperr.CodeCategoryCycle},
+		{"move under child", 1, 2, apperr.CodeCategoryCycle},
+		{"move under grandchild", 1, 3, apperr.CodeCategoryCycle},
+		{"missing parent", 2, 99, apperr.CodeInvalidParentCategory},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.checkCategoryParent(context.Background(), tt.categoryID, tt.parentID)
+			if tt.wantCode == "" {
+				if err != nil {
+					t.Fatalf("err = %v, want nil", err)
+				}
+				return
+			}
+			var appErr *apperr.Error
+			if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+				t.Errorf("err = %v, want code %s", err, tt.wantCode)
+			}
+		})
+	}
+}
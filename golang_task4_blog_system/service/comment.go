@@ -0,0 +1,297 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+// CommentInput 发表和修改评论的参数，ParentID 仅发表时使用；修改时 PostID 为 0 表示不移动
+type CommentInput struct {
+	PostID   uint
+	ParentID *uint
+	Content  string
+}
+
+// CommentNode 评论树节点，reply_count 为直接回复数，
+// 超过深度限制的回复不会展开，可根据 reply_count 判断是否还有更多回复
+type CommentNode struct {
+	models.Comment
+	Replies    []*CommentNode `json:"replies"`
+	ReplyCount int64          `json:"reply_count"`
+}
+
+// CommentService 评论的发表、查看和管理，actor 为当前用户，匿名访问时为 nil
+type CommentService interface {
+	Create(ctx context.Context, actor *models.User, input CommentInput) (*models.Comment, error)
+	// ListForPost 返回 actor 可见文章下已审核通过的评论，rootsOnly 为 true 时只返回顶层评论
+	ListForPost(ctx context.Context, actor *models.User, postID uint, rootsOnly bool, page repository.Scope) ([]models.Comment, int64, error)
+	// BuildTree 逐层加载已审核通过的回复，每层一次查询，最多展开 depth 层
+	BuildTree(ctx context.Context, roots []models.Comment, depth int) ([]*CommentNode, error)
+	// Get 获取评论详情，未通过审核的评论仅评论作者和审核者可见
+	Get(ctx context.Context, actor *models.User, id uint) (*models.Comment, error)
+	Update(ctx context.Context, actor *models.User, id uint, input CommentInput) (*models.Comment, error)
+	Delete(ctx context.Context, actor *models.User, id uint) error
+	ListByUser(ctx context.Context, userID uint) ([]models.Comment, int64, error)
+	// Moderate 把评论的审核状态改为 status，限文章作者或拥有 comments:moderate:any 权限的用户
+	Moderate(ctx context.Context, actor *models.User, id uint, status string) (*models.Comment, error)
+	// ModerateBatch 批量审核评论，ids 中的评论必须全部存在且有权审核，否则整体拒绝，
+	// 错误的 meta.comment_ids 为不存在或无权审核的评论。返回更新的评论数
+	ModerateBatch(ctx context.Context, actor *models.User, ids []uint, status string) (int64, error)
+	// ModerationQueue 返回某审核状态的当前页评论（包含作者）和评论总数：拥有 comments:moderate:any 权限可见全部，
+	// 其他用户只看到自己文章下的评论；postID 为 0 时不按文章筛选
+	ModerationQueue(ctx context.Context, actor *models.User, status string, postID uint, page repository.Scope) ([]models.Comment, int64, error)
+}
+
+type commentService struct {
+	comments repository.CommentRepository
+	posts    repository.PostRepository
+}
+
+// NewCommentService 创建评论服务，文章仓储用于检查评论所属文章
+func NewCommentService(comments repository.CommentRepository, posts repository.PostRepository) CommentService {
+	return &commentService{comments: comments, posts: posts}
+}
+
+func (s *commentService) Create(ctx context.Context, actor *models.User, input CommentInput) (*models.Comment, error) {
+	if actor == nil {
+		return nil, apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证才能发表评论")
+	}
+
+	// 检查文章是否存在
+	post, err := s.visiblePost(ctx, actor, input.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if !post.IsPublished() {
+		return nil, apperr.Validation(apperr.CodePostNotPublished, "文章未发布，暂不能评论")
+	}
+
+	// 回复评论时，父评论必须属于同一篇文章
+	if input.ParentID != nil {
+		parent, err := s.comments.FindByID(ctx, *input.ParentID)
+		if err != nil && !errors.Is(err, repository.ErrNotFound) {
+			return nil, apperr.Internal("检查评论失败", err)
+		}
+		if err != nil || parent.Status != models.CommentStatusApproved {
+			return nil, apperr.NotFound(apperr.CodeCommentNotFound, "回复的评论不存在").
+				WithFields(apperr.Field("parent_id", "not_found", "回复的评论不存在"))
+		}
+		if parent.PostID != input.PostID {
+			return nil, apperr.Validation(apperr.CodeInvalidParentComment, "回复的评论不属于该文章").
+				WithFields(apperr.Field("parent_id", "mismatch", "回复的评论不属于该文章"))
+		}
+	}
+
+	// 新评论默认待审核，有权审核该文章评论的用户发表的评论直接通过
+	status := models.CommentStatusPending
+	if authorized(actor, post.UserID, "comments:moderate") {
+		status = models.CommentStatusApproved
+	}
+
+	comment := models.Comment{
+		Content:  input.Content,
+		Status:   status,
+		UserID:   actor.ID,
+		PostID:   input.PostID,
+		ParentID: input.ParentID,
+	}
+	if err := s.comments.Create(ctx, &comment); err != nil {
+		return nil, apperr.Internal("创建评论失败", err)
+	}
+
+	// 返回包含用户信息的评论
+	created, err := s.comments.FindWithUser(ctx, comment.ID)
+	if err != nil {
+		return nil, apperr.Internal("获取评论详情失败", err)
+	}
+	return created, nil
+}
+
+func (s *commentService) ListForPost(ctx context.Context, actor *models.User, postID uint, rootsOnly bool, page repository.Scope) ([]models.Comment, int64, error) {
+	if _, err := s.visiblePost(ctx, actor, postID); err != nil {
+		return nil, 0, err
+	}
+
+	comments, total, err := s.comments.ListApproved(ctx, postID, rootsOnly, page)
+	if err != nil {
+		return nil, 0, apperr.Internal("获取评论列表失败", err)
+	}
+	return comments, total, nil
+}
+
+func (s *commentService) BuildTree(ctx context.Context, roots []models.Comment, depth int) ([]*CommentNode, error) {
+	tree := make([]*CommentNode, 0, len(roots))
+	level := make(map[uint]*CommentNode, len(roots))
+	for _, root := range roots {
+		node := &CommentNode{Comment: root, Replies: []*CommentNode{}}
+		tree = append(tree, node)
+		level[root.ID] = node
+	}
+
+	for d := 1; len(level) > 0; d++ {
+		parentIDs := make([]uint, 0, len(level))
+		for id := range level {
+			parentIDs = append(parentIDs, id)
+		}
+
+		// 统计每个节点的直接回复数
+		counts, err := s.comments.CountApprovedReplies(ctx, parentIDs)
+		if err != nil {
+			return nil, apperr.Internal("获取评论回复失败", err)
+		}
+		for id, total := range counts {
+			level[id].ReplyCount = total
+		}
+
+		if d >= depth {
+			break
+		}
+
+		replies, err := s.comments.ApprovedReplies(ctx, parentIDs)
+		if err != nil {
+			return nil, apperr.Internal("获取评论回复失败", err)
+		}
+
+		next := make(map[uint]*CommentNode, len(replies))
+		for _, reply := range replies {
+			node := &CommentNode{Comment: reply, Replies: []*CommentNode{}}
+			parent := level[*reply.ParentID]
+			parent.Replies = append(parent.Replies, node)
+			next[reply.ID] = node
+		}
+		level = next
+	}
+
+	return tree, nil
+}
+
+func (s *commentService) Get(ctx context.Context, actor *models.User, id uint) (*models.Comment, error) {
+	comment, err := s.comments.FindDetail(ctx, id)
+	if err != nil {
+		return nil, commentLookupError(err, "获取评论失败")
+	}
+
+	if comment.Status != models.CommentStatusApproved &&
+		(actor == nil || comment.UserID != actor.ID) &&
+		!authorized(actor, comment.Post.UserID, "comments:moderate") {
+		return nil, errCommentNotFound()
+	}
+	return comment, nil
+}
+
+func (s *commentService) Update(ctx context.Context, actor *models.User, id uint, input CommentInput) (*models.Comment, error) {
+	comment, err := s.comments.FindByID(ctx, id)
+	if err != nil {
+		return nil, commentLookupError(err, "查找评论失败")
+	}
+
+	// 检查权限：评论作者或拥有 comments:update:any 权限的用户可以更新
+	if !authorized(actor, comment.UserID, "comments:update") {
+		return nil, apperr.Forbidden(apperr.CodeForbidden, "无权更新此评论")
+	}
+
+	// 未指定文章时不移动
+	if input.PostID == 0 {
+		input.PostID = comment.PostID
+	}
+
+	// 移动到其他文章时检查目标文章，与发表评论相同，目标文章须对 actor 可见且已发布
+	if input.PostID != comment.PostID {
+		// 楼中楼评论不能单独移动到其他文章
+		replyCount, err := s.comments.CountReplies(ctx, comment.ID)
+		if err != nil {
+			return nil, apperr.Internal("查找评论失败", err)
+		}
+		if comment.ParentID != nil || replyCount > 0 {
+			return nil, apperr.Validation(apperr.CodeCommentNotMovable, "回复中的评论不能移动到其他文章")
+		}
+
+		target, err := s.visiblePost(ctx, actor, input.PostID)
+		if err != nil {
+			if apperr.IsKind(err, apperr.KindNotFound) {
+				return nil, apperr.NotFound(apperr.CodePostNotFound, "目标文章不存在").
+					WithFields(apperr.Field("post_id", "not_found", "目标文章不存在"))
+			}
+			return nil, err
+		}
+		if !target.IsPublished() {
+			return nil, apperr.Validation(apperr.CodePostNotPublished, "目标文章未发布，不能移动评论").
+				WithFields(apperr.Field("post_id", "not_published", "目标文章未发布"))
+		}
+	}
+
+	comment.Content = input.Content
+	comment.PostID = input.PostID
+
+	// 非审核者修改后的评论需要重新审核
+	post, err := s.posts.FindByID(ctx, comment.PostID)
+	if err != nil {
+		return nil, apperr.Internal("检查文章失败", err)
+	}
+	if !authorized(actor, post.UserID, "comments:moderate") {
+		comment.Status = models.CommentStatusPending
+	}
+
+	if err := s.comments.Save(ctx, comment); err != nil {
+		return nil, apperr.Internal("更新评论失败", err)
+	}
+
+	// 重新获取更新后的评论
+	updated, err := s.comments.FindWithUser(ctx, comment.ID)
+	if err != nil {
+		return nil, apperr.Internal("获取评论详情失败", err)
+	}
+	return updated, nil
+}
+
+func (s *commentService) Delete(ctx context.Context, actor *models.User, id uint) error {
+	comment, err := s.comments.FindByID(ctx, id)
+	if err != nil {
+		return commentLookupError(err, "查找评论失败")
+	}
+
+	// 检查权限：评论作者或拥有 comments:delete:any 权限的用户可以删除
+	if !authorized(actor, comment.UserID, "comments:delete") {
+		return apperr.Forbidden(apperr.CodeForbidden, "无权删除此评论")
+	}
+
+	if err := s.comments.Delete(ctx, comment); err != nil {
+		return apperr.Internal("删除评论失败", err)
+	}
+	return nil
+}
+
+func (s *commentService) ListByUser(ctx context.Context, userID uint) ([]models.Comment, int64, error) {
+	comments, total, err := s.comments.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, 0, apperr.Internal("获取评论失败", err)
+	}
+	return comments, total, nil
+}
+
+// visiblePost 查找评论所属文章，不存在或 actor 无权查看时返回 404
+func (s *commentService) visiblePost(ctx context.Context, actor *models.User, postID uint) (*models.Post, error) {
+	post, err := s.posts.FindByID(ctx, postID)
+	if err != nil {
+		return nil, postLookupError(err, "检查文章失败")
+	}
+	if !canViewPost(actor, post) {
+		return nil, errPostNotFound()
+	}
+	return post, nil
+}
+
+func errCommentNotFound() *apperr.Error {
+	return apperr.NotFound(apperr.CodeCommentNotFound, "评论不存在")
+}
+
+// commentLookupError 把查找评论时的仓储错误转换为 404 或 500
+func commentLookupError(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return errCommentNotFound()
+	}
+	return apperr.Internal(message, err)
+}
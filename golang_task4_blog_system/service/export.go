@@ -0,0 +1,255 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"io"
+	"time"
+)
+
+// 数据导出格式
+const (
+	ExportFormatZIP  = "zip"
+	ExportFormatJSON = "json"
+)
+
+// ExportService 导出用户的个人数据：账户资料、文章和评论。
+// 数据分批从数据库读取并直接写入 w，不在内存中生成完整的导出文件
+type ExportService interface {
+	// Export 按 format 写出 user 的数据。zip 格式包含 user.json、posts.json 和 comments.json，
+	// json 格式为包含 user、posts 和 comments 的单个对象。
+	// 写出过程中出错时 w 中的内容不完整，调用方无法再返回错误响应
+	Export(ctx context.Context, user *models.User, format string, w io.Writer) error
+}
+
+type exportService struct {
+	posts    repository.PostRepository
+	comments repository.CommentRepository
+}
+
+// NewExportService 创建数据导出服务
+func NewExportService(posts repository.PostRepository, comments repository.CommentRepository) ExportService {
+	return &exportService{posts: posts, comments: comments}
+}
+
+// exportedUser 导出的账户资料，包含公开资料以外的邮箱和角色
+type exportedUser struct {
+	ID              uint       `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	Role            string     `json:"role"`
+	DisplayName     string     `json:"display_name"`
+	Bio             string     `json:"bio"`
+	AvatarURL       string     `json:"avatar_url"`
+}
+
+type exportedPost struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	Slug        string     `json:"slug"`
+	Content     string     `json:"content"`
+	Status      string     `json:"status"`
+	Category    string     `json:"category,omitempty"`
+	Tags        []string   `json:"tags"`
+	PublishAt   *time.Time `json:"publish_at,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+type exportedComment struct {
+	ID        uint      `json:"id"`
+	PostID    uint      `json:"post_id"`
+	ParentID  *uint     `json:"parent_id"`
+	Content   string    `json:"content"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *exportService) Export(ctx context.Context, user *models.User, format string, w io.Writer) error {
+	profile := exportedUser{
+		ID:              user.ID,
+		Username:        user.Username,
+		Email:           user.Email,
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		Role:            user.Role,
+		DisplayName:     user.DisplayName,
+		Bio:             user.Bio,
+		AvatarURL:       user.AvatarURL,
+	}
+
+	if format == ExportFormatJSON {
+		return s.writeJSON(ctx, user.ID, profile, w)
+	}
+	return s.writeZIP(ctx, user.ID, profile, w)
+}
+
+func (s *exportService) writeZIP(ctx context.Context, userID uint, profile exportedUser, w io.Writer) error {
+	zw := zip.NewWriter(w)
+	now := time.Now()
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+	}
+
+	f, err := create("user.json")
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(profile); err != nil {
+		return err
+	}
+
+	if f, err = create("posts.json"); err != nil {
+		return err
+	}
+	if err := s.writePosts(ctx, userID, f); err != nil {
+		return err
+	}
+
+	if f, err = create("comments.json"); err != nil {
+		return err
+	}
+	if err := s.writeComments(ctx, userID, f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+func (s *exportService) writeJSON(ctx context.Context, userID uint, profile exportedUser, w io.Writer) error {
+	data, err := json.Marshal(profile)
+	if err != nil {
+		return err
+	}
+	ew := &errWriter{w: w}
+	ew.write(`{"exported_at":"` + time.Now().UTC().Format(time.RFC3339) + `","user":` + string(data) + `,"posts":`)
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := s.writePosts(ctx, userID, w); err != nil {
+		return err
+	}
+	ew.write(`,"comments":`)
+	if ew.err != nil {
+		return ew.err
+	}
+	if err := s.writeComments(ctx, userID, w); err != nil {
+		return err
+	}
+	ew.write("}\n")
+	return ew.err
+}
+
+func (s *exportService) writePosts(ctx context.Context, userID uint, w io.Writer) error {
+	arr := newJSONArray(w)
+	err := s.posts.EachByUser(ctx, userID, func(posts []models.Post) error {
+		for i := range posts {
+			p := &posts[i]
+			item := exportedPost{
+				ID:          p.ID,
+				Title:       p.Title,
+				Slug:        p.Slug,
+				Content:     p.Content,
+				Status:      p.Status,
+				Tags:        make([]string, 0, len(p.Tags)),
+				PublishAt:   p.PublishAt,
+				PublishedAt: p.PublishedAt,
+				CreatedAt:   p.CreatedAt,
+				UpdatedAt:   p.UpdatedAt,
+			}
+			if p.Category != nil {
+				item.Category = p.Category.Name
+			}
+			for _, tag := range p.Tags {
+				item.Tags = append(item.Tags, tag.Name)
+			}
+			if err := arr.add(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return arr.close()
+}
+
+func (s *exportService) writeComments(ctx context.Context, userID uint, w io.Writer) error {
+	arr := newJSONArray(w)
+	err := s.comments.EachByUser(ctx, userID, func(comments []models.Comment) error {
+		for _, c := range comments {
+			err := arr.add(exportedComment{
+				ID:        c.ID,
+				PostID:    c.PostID,
+				ParentID:  c.ParentID,
+				Content:   c.Content,
+				Status:    c.Status,
+				CreatedAt: c.CreatedAt,
+				UpdatedAt: c.UpdatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return arr.close()
+}
+
+// jsonArray 逐个写出 JSON 数组的元素
+type jsonArray struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONArray(w io.Writer) *jsonArray {
+	return &jsonArray{w: w}
+}
+
+func (a *jsonArray) add(v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if a.count == 0 {
+		sep = "[\n"
+	}
+	a.count++
+	if _, err := io.WriteString(a.w, sep); err != nil {
+		return err
+	}
+	_, err = a.w.Write(data)
+	return err
+}
+
+func (a *jsonArray) close() error {
+	end := "\n]"
+	if a.count == 0 {
+		end = "[]"
+	}
+	_, err := io.WriteString(a.w, end)
+	return err
+}
+
+// errWriter 记录第一个写入错误，之后的写入直接忽略
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) write(s string) {
+	if e.err == nil {
+		_, e.err = io.WriteString(e.w, s)
+	}
+}
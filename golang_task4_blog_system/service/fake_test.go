@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+// fakePosts 内存文章仓储，只实现测试用到的方法，
+// 其余方法由嵌入的接口提供，被调用时因接口为 nil 而 panic
+type fakePosts struct {
+	repository.PostRepository
+	categories map[uint]*models.Category
+	tagCounts  []repository.TagCount
+}
+
+func (r *fakePosts) FindCategory(ctx context.Context, id uint) (*models.Category, error) {
+	category, ok := r.categories[id]
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	copied := *category
+	return &copied, nil
+}
+
+func (r *fakePosts) TagCloud(ctx context.Context, limit int, byName bool) ([]repository.TagCount, error) {
+	return r.tagCounts, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+func (s *commentService) Moderate(ctx context.Context, actor *models.User, id uint, status string) (*models.Comment, error) {
+	comment, err := s.comments.FindByID(ctx, id)
+	if err != nil {
+		return nil, commentLookupError(err, "查找评论失败")
+	}
+
+	// 检查权限：文章作者、编辑或管理员可以审核
+	allowed, err := s.canModerate(ctx, actor, comment.PostID)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, apperr.Forbidden(apperr.CodeForbidden, "无权审核此评论")
+	}
+
+	if _, err := s.comments.UpdateStatus(ctx, []uint{comment.ID}, status); err != nil {
+		return nil, apperr.Internal("审核评论失败", err)
+	}
+
+	updated, err := s.comments.FindWithUser(ctx, comment.ID)
+	if err != nil {
+		return nil, apperr.Internal("获取评论详情失败", err)
+	}
+	return updated, nil
+}
+
+func (s *commentService) ModerateBatch(ctx context.Context, actor *models.User, ids []uint, status string) (int64, error) {
+	comments, err := s.comments.FindByIDs(ctx, ids)
+	if err != nil {
+		return 0, apperr.Internal("查找评论失败", err)
+	}
+
+	found := make(map[uint]bool, len(comments))
+	for _, comment := range comments {
+		found[comment.ID] = true
+	}
+	var missing []uint
+	for _, id := range ids {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return 0, errCommentNotFound().WithMeta("comment_ids", missing)
+	}
+
+	// 检查权限：按文章缓存判断结果
+	allowedPosts := make(map[uint]bool)
+	var forbidden []uint
+	for _, comment := range comments {
+		allowed, checked := allowedPosts[comment.PostID]
+		if !checked {
+			if allowed, err = s.canModerate(ctx, actor, comment.PostID); err != nil {
+				return 0, err
+			}
+			allowedPosts[comment.PostID] = allowed
+		}
+		if !allowed {
+			forbidden = append(forbidden, comment.ID)
+		}
+	}
+	if len(forbidden) > 0 {
+		return 0, apperr.Forbidden(apperr.CodeForbidden, "无权审核部分评论").
+			WithMeta("comment_ids", forbidden)
+	}
+
+	updated, err := s.comments.UpdateStatus(ctx, ids, status)
+	if err != nil {
+		return 0, apperr.Internal("批量审核失败", err)
+	}
+	return updated, nil
+}
+
+func (s *commentService) ModerationQueue(ctx context.Context, actor *models.User, status string, postID uint, page repository.Scope) ([]models.Comment, int64, error) {
+	if actor == nil {
+		return nil, 0, apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证")
+	}
+
+	filter := repository.ModerationFilter{Status: status, PostID: postID}
+	if !actor.Can(models.PermCommentsModerateAny) {
+		filter.PostAuthorID = actor.ID
+	}
+
+	comments, total, err := s.comments.ListForModeration(ctx, filter, page)
+	if err != nil {
+		return nil, 0, apperr.Internal("获取评论列表失败", err)
+	}
+	return comments, total, nil
+}
+
+// canModerate 检查 actor 能否审核文章下的评论：
+// 拥有 comments:moderate:any，或者是文章作者且拥有 comments:moderate:own
+func (s *commentService) canModerate(ctx context.Context, actor *models.User, postID uint) (bool, error) {
+	post, err := s.posts.FindByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return false, nil
+		}
+		return false, apperr.Internal("检查文章失败", err)
+	}
+	return authorized(actor, post.UserID, "comments:moderate"), nil
+}
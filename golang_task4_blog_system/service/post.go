@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	maxPostTags     = 20
+	maxTagNameRunes = 50
+)
+
+// PostInput 创建和更新文章的参数。更新时空字段保持不变；
+// Tags 为 nil 时不修改标签，为空时清空；CategoryID 为 0 表示不分类
+type PostInput struct {
+	Title      string
+	Content    string
+	Slug       string
+	Status     string
+	PublishAt  *time.Time
+	CategoryID *uint
+	Tags       []string
+}
+
+// PostService 文章的发布、查看和管理，actor 为当前用户，匿名访问时为 nil
+type PostService interface {
+	Create(ctx context.Context, actor *models.User, input PostInput) (*models.Post, error)
+	List(ctx context.Context, filter repository.PostFilter, page repository.Scope) ([]models.Post, int64, error)
+	// Get 获取文章详情，actor 无权查看的文章按不存在处理
+	Get(ctx context.Context, actor *models.User, id uint) (*models.Post, error)
+	// GetBySlug 通过 slug 获取文章详情；slug 是文章的旧 slug 时 moved 为 true，
+	// 返回的文章不含详情，调用方应跳转到当前 slug
+	GetBySlug(ctx context.Context, actor *models.User, slug string) (post *models.Post, moved bool, err error)
+	Update(ctx context.Context, actor *models.User, id uint, input PostInput) (*models.Post, error)
+	Delete(ctx context.Context, actor *models.User, id uint) error
+
+	// Categories 返回分类树及各分类的已发布文章数
+	Categories(ctx context.Context) ([]*CategoryNode, error)
+	CreateCategory(ctx context.Context, input CategoryInput) (*models.Category, error)
+	UpdateCategory(ctx context.Context, id uint, input CategoryInput) (*models.Category, error)
+	// DeleteCategory 删除分类，其子分类和文章移动到上一级分类
+	DeleteCategory(ctx context.Context, id uint) error
+	// TagCloud 标签云，只统计已发布的文章，返回文章数最多的 limit 个标签；
+	// byName 为 true 时按名称排序，否则按文章数倒序
+	TagCloud(ctx context.Context, limit int, byName bool) ([]TagCloudItem, error)
+	// Search 全文搜索已发布的文章和已审核通过的评论，按相关度排序；kinds 为空时搜索全部类型
+	Search(ctx context.Context, q string, kinds ...string) ([]SearchResult, error)
+}
+
+type postService struct {
+	posts    repository.PostRepository
+	comments repository.CommentRepository
+}
+
+// NewPostService 创建文章服务，评论仓储用于校验搜索命中的评论
+func NewPostService(posts repository.PostRepository, comments repository.CommentRepository) PostService {
+	return &postService{posts: posts, comments: comments}
+}
+
+func (s *postService) Create(ctx context.Context, actor *models.User, input PostInput) (*models.Post, error) {
+	if actor == nil {
+		return nil, apperr.Unauthorized(apperr.CodeUnauthorized, "需要认证才能创建文章")
+	}
+
+	// 计算发布状态
+	now := time.Now()
+	status, err := resolvePostStatus(input.Status, input.PublishAt, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// 整理标签，检查分类
+	tagNames, err := normalizeTagNames(input.Tags)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkCategory(ctx, input.CategoryID); err != nil {
+		return nil, err
+	}
+
+	// slug 可由请求指定，否则取标题，重复时由仓储追加序号
+	slugSource := input.Title
+	if input.Slug != "" {
+		slugSource = input.Slug
+	}
+
+	post := models.Post{
+		Title:   input.Title,
+		Content: input.Content,
+		Slug:    models.Slugify(slugSource),
+		Status:  status,
+		UserID:  actor.ID,
+	}
+	if input.CategoryID != nil && *input.CategoryID != 0 {
+		post.CategoryID = input.CategoryID
+	}
+	switch status {
+	case models.PostStatusScheduled:
+		post.PublishAt = utcTime(input.PublishAt)
+	case models.PostStatusPublished:
+		post.PublishedAt = &now
+	}
+
+	if err := s.posts.Create(ctx, &post, tagNames); err != nil {
+		return nil, apperr.Internal("创建文章失败", err)
+	}
+
+	// 返回包含用户、分类和标签的文章
+	created, err := s.posts.FindWithRelations(ctx, post.ID)
+	if err != nil {
+		return nil, apperr.Internal("获取文章详情失败", err)
+	}
+	return created, nil
+}
+
+func (s *postService) List(ctx context.Context, filter repository.PostFilter, page repository.Scope) ([]models.Post, int64, error) {
+	posts, total, err := s.posts.List(ctx, filter, page)
+	if err != nil {
+		return nil, 0, apperr.Internal("获取文章列表失败", err)
+	}
+	return posts, total, nil
+}
+
+func (s *postService) Get(ctx context.Context, actor *models.User, id uint) (*models.Post, error) {
+	post, err := s.posts.FindDetail(ctx, id)
+	if err != nil {
+		return nil, postLookupError(err, "获取文章失败")
+	}
+	// 草稿、定时和归档文章仅作者可见
+	if !canViewPost(actor, post) {
+		return nil, errPostNotFound()
+	}
+	return post, nil
+}
+
+func (s *postService) GetBySlug(ctx context.Context, actor *models.User, slug string) (*models.Post, bool, error) {
+	post, err := s.posts.FindDetailBySlug(ctx, slug)
+	moved := false
+	if errors.Is(err, repository.ErrNotFound) {
+		// 查找历史 slug
+		post, err = s.posts.FindByHistorySlug(ctx, slug)
+		moved = true
+	}
+	if err != nil {
+		return nil, false, postLookupError(err, "获取文章失败")
+	}
+	if !canViewPost(actor, post) {
+		return nil, false, errPostNotFound()
+	}
+	return post, moved, nil
+}
+
+func (s *postService) Update(ctx context.Context, actor *models.User, id uint, input PostInput) (*models.Post, error) {
+	post, err := s.posts.FindByID(ctx, id)
+	if err != nil {
+		return nil, postLookupError(err, "查找文章失败")
+	}
+
+	// 检查权限：文章作者或拥有 posts:update:any 权限的用户可以更新
+	if !authorized(actor, post.UserID, "posts:update") {
+		return nil, apperr.Forbidden(apperr.CodeForbidden, "无权更新此文章")
+	}
+
+	// 构建更新数据
+	changes := repository.PostChanges{Fields: make(map[string]interface{})}
+	if input.Title != "" {
+		changes.Fields["title"] = input.Title
+	}
+	if input.Content != "" {
+		changes.Fields["content"] = input.Content
+	}
+
+	// 修改分类和标签
+	if input.CategoryID != nil {
+		if err := s.checkCategory(ctx, input.CategoryID); err != nil {
+			return nil, err
+		}
+		if *input.CategoryID == 0 {
+			changes.Fields["category_id"] = nil
+		} else {
+			changes.Fields["category_id"] = *input.CategoryID
+		}
+	}
+	if input.Tags != nil {
+		if changes.Tags, err = normalizeTagNames(input.Tags); err != nil {
+			return nil, err
+		}
+		changes.ReplaceTags = true
+	}
+
+	// 修改发布状态
+	if input.Status != "" || input.PublishAt != nil {
+		now := time.Now()
+		status, err := resolvePostStatus(input.Status, input.PublishAt, now)
+		if err != nil {
+			return nil, err
+		}
+		changes.Fields["status"] = status
+		switch status {
+		case models.PostStatusScheduled:
+			changes.Fields["publish_at"] = utcTime(input.PublishAt)
+		case models.PostStatusPublished:
+			changes.Fields["publish_at"] = nil
+			if post.PublishedAt == nil {
+				changes.Fields["published_at"] = now
+			}
+		}
+	}
+
+	// 指定了 slug 或修改了标题时重新生成 slug，旧 slug 记入历史
+	if input.Slug != "" {
+		changes.Slug = models.Slugify(input.Slug)
+	} else if input.Title != "" && input.Title != post.Title {
+		changes.Slug = models.Slugify(input.Title)
+	}
+
+	if err := s.posts.Update(ctx, post, changes); err != nil {
+		return nil, apperr.Internal("更新文章失败", err)
+	}
+
+	// 重新获取更新后的文章
+	updated, err := s.posts.FindWithRelations(ctx, post.ID)
+	if err != nil {
+		return nil, apperr.Internal("获取文章详情失败", err)
+	}
+	return updated, nil
+}
+
+func (s *postService) Delete(ctx context.Context, actor *models.User, id uint) error {
+	post, err := s.posts.FindByID(ctx, id)
+	if err != nil {
+		return postLookupError(err, "查找文章失败")
+	}
+
+	// 检查权限：文章作者或拥有 posts:delete:any 权限的用户可以删除
+	if !authorized(actor, post.UserID, "posts:delete") {
+		return apperr.Forbidden(apperr.CodeForbidden, "无权删除此文章")
+	}
+
+	if err := s.posts.Delete(ctx, post); err != nil {
+		return apperr.Internal("删除文章失败", err)
+	}
+	return nil
+}
+
+// checkCategory 检查文章指定的分类是否存在，0 或未指定表示不分类
+func (s *postService) checkCategory(ctx context.Context, categoryID *uint) error {
+	if categoryID == nil || *categoryID == 0 {
+		return nil
+	}
+	exists, err := s.posts.CategoryExists(ctx, *categoryID)
+	if err != nil {
+		return apperr.Internal("查找分类失败", err)
+	}
+	if !exists {
+		return apperr.Validation(apperr.CodeInvalidCategory, "分类不存在").
+			WithFields(apperr.Field("category_id", "not_found", "分类不存在"))
+	}
+	return nil
+}
+
+func errPostNotFound() *apperr.Error {
+	return apperr.NotFound(apperr.CodePostNotFound, "文章不存在")
+}
+
+// postLookupError 把查找文章时的仓储错误转换为 404 或 500
+func postLookupError(err error, message string) error {
+	if errors.Is(err, repository.ErrNotFound) {
+		return errPostNotFound()
+	}
+	return apperr.Internal(message, err)
+}
+
+// resolvePostStatus 根据请求中的 status 和 publish_at 计算文章状态：
+// 未指定 status 时，publish_at 在未来则定时发布，否则立即发布
+func resolvePostStatus(status string, publishAt *time.Time, now time.Time) (string, error) {
+	switch status {
+	case "":
+		if publishAt != nil && publishAt.After(now) {
+			return models.PostStatusScheduled, nil
+		}
+		return models.PostStatusPublished, nil
+	case models.PostStatusScheduled:
+		if publishAt == nil || !publishAt.After(now) {
+			return "", apperr.InvalidField("publish_at", "future", "定时发布需要指定未来的 publish_at")
+		}
+	}
+	return status, nil
+}
+
+// utcTime 定时发布时间统一以 UTC 保存，SQLite 按字符串比较时间，带不同时区偏移的值无法正确比较
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
+
+// normalizeTagNames 整理请求中的标签名：去掉首尾空白、合并连续空白，
+// 按 slug 去重（"Go" 和 "go" 视为同一标签）
+func normalizeTagNames(names []string) ([]string, error) {
+	if len(names) > maxPostTags {
+		return nil, apperr.InvalidField("tags", "max", fmt.Sprintf("标签不能超过 %d 个", maxPostTags))
+	}
+
+	seen := make(map[string]bool, len(names))
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		if name == "" {
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTagNameRunes {
+			return nil, apperr.InvalidField("tags", "max",
+				fmt.Sprintf("标签 %q 不能超过 %d 个字符", name, maxTagNameRunes))
+		}
+		slug := models.SlugWords(name)
+		if slug == "" {
+			return nil, apperr.InvalidField("tags", "slug",
+				fmt.Sprintf("标签 %q 必须包含字母或数字", name))
+		}
+		if !seen[slug] {
+			seen[slug] = true
+			result = append(result, name)
+		}
+	}
+	return result, nil
+}
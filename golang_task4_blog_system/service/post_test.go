@@ -0,0 +1,138 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+)
+
+func TestResolvePostStatus(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		status    string
+		publishAt *time.Time
+		want      string
+		wantErr   bool
+	}{
+		{"default publishes now", "", nil, models.PostStatusPublished, false},
+		{"past publish_at publishes now", "", &past, models.PostStatusPublished, false},
+		{"publish_at equal to now publishes now", "", &now, models.PostStatusPublished, false},
+		{"future publish_at schedules", "", &future, models.PostStatusScheduled, false},
+		{"explicit draft", models.PostStatusDraft, &future, models.PostStatusDraft, false},
+		{"explicit published", models.PostStatusPublished, &future, models.PostStatusPublished, false},
+		{"scheduled in future", models.PostStatusScheduled, &future, models.PostStatusScheduled, false},
+		{"scheduled without publish_at", models.PostStatusScheduled, nil, "", true},
+		{"scheduled in past", models.PostStatusScheduled, &past, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolvePostStatus(tt.status, tt.publishAt, now)
+			if tt.wantErr {
+				if !apperr.IsKind(err, apperr.KindValidation) {
+					t.Fatalf("err = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("status = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTCTime(t *testing.T) {
+	if utcTime(nil) != nil {
+		t.Error("utcTime(nil) should be nil")
+	}
+	local := time.Date(2026, 5, 1, 20, 0, 0, 0, time.FixedZone("CST", 8*3600))
+	got := utcTime(&local)
+	if got.Location() != time.UTC || !got.Equal(local) {
+		t.Errorf("utcTime = %v, want %v in UTC", got, local)
+	}
+}
+
+func TestNormalizeTagNames(t *testing.T) {
+	tooMany := make([]string, maxPostTags+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("tag%d", i)
+	}
+
+	tests := []struct {
+		name    string
+		in      []string
+		want    []string
+		wantErr bool
+	}{
+		{"nil", nil, []string{}, false},
+		{"trims and collapses spaces", []string{"  Go   Lang ", "web"}, []string{"Go Lang", "web"}, false},
+		{"skips blanks", []string{"", "  ", "go"}, []string{"go"}, false},
+		{"dedupes by slug keeping first", []string{"Go", "go", "GO "}, []string{"Go"}, false},
+		{"dedupes punctuation variants", []string{"go-lang", "Go Lang"}, []string{"go-lang"}, false},
+		{"max tags allowed", tooMany[:maxPostTags], tooMany[:maxPostTags], false},
+		{"too many tags", tooMany, nil, true},
+		{"name too long", []string{strings.Repeat("标", maxTagNameRunes+1)}, nil, true},
+		{"no letters or digits", []string{"!!!"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := normalizeTagNames(tt.in)
+			if tt.wantErr {
+				if !apperr.IsKind(err, apperr.KindValidation) {
+					t.Fatalf("err = %v, want validation error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeTagNames(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTagCloud(t *testing.T) {
+	posts := &fakePosts{tagCounts: []repository.TagCount{
+		{ID: 1, Name: "go", Slug: "go", PostCount: 9},
+		{ID: 2, Name: "web", Slug: "web", PostCount: 5},
+		{ID: 3, Name: "sql", Slug: "sql", PostCount: 1},
+	}}
+	s := NewPostService(posts, nil)
+
+	tags, err := s.TagCloud(context.Background(), 10, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var weights []int
+	for _, tag := range tags {
+		weights = append(weights, tag.Weight)
+	}
+	if !slices.Equal(weights, []int{5, 3, 1}) {
+		t.Errorf("weights = %v, want [5 3 1]", weights)
+	}
+
+	// 文章数相同时字号统一为最小等级；没有标签时返回空数组而不是 nil
+	posts.tagCounts = []repository.TagCount{{ID: 1, Name: "go", PostCount: 2}, {ID: 2, Name: "web", PostCount: 2}}
+	if tags, _ := s.TagCloud(context.Background(), 10, false); tags[0].Weight != 1 || tags[1].Weight != 1 {
+		t.Errorf("equal counts weights = %v", tags)
+	}
+	posts.tagCounts = nil
+	if tags, err := s.TagCloud(context.Background(), 10, false); err != nil || tags == nil {
+		t.Errorf("empty cloud = %v (err %v), want empty slice", tags, err)
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/repository"
+	"log/slog"
+	"time"
+)
+
+// RefreshTokenTTL 刷新令牌有效期
+var RefreshTokenTTL = 30 * 24 * time.Hour
+
+func (s *userService) IssueRefreshToken(ctx context.Context, user *models.User) (string, error) {
+	familyID, err := randomToken(16)
+	if err != nil {
+		return "", apperr.Internal("生成令牌失败", err)
+	}
+
+	token, record, err := newRefreshToken(user.ID, familyID)
+	if err != nil {
+		return "", apperr.Internal("生成令牌失败", err)
+	}
+	if err := s.tokens.Create(ctx, record); err != nil {
+		return "", apperr.Internal("生成令牌失败", err)
+	}
+	return token, nil
+}
+
+func (s *userService) RefreshToken(ctx context.Context, token string) (*models.User, string, error) {
+	current, err := s.tokens.FindByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, "", errRefreshTokenInvalid()
+		}
+		return nil, "", apperr.Internal("刷新令牌失败", err)
+	}
+
+	now := time.Now()
+	if current.RevokedAt != nil || current.ReplacedByID != nil {
+		return nil, "", s.refreshTokenReused(ctx, current.FamilyID, now)
+	}
+	if !current.IsActive(now) {
+		return nil, "", errRefreshTokenInvalid()
+	}
+
+	user, err := s.users.FindByID(ctx, current.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, "", errRefreshTokenInvalid()
+		}
+		return nil, "", apperr.Internal("刷新令牌失败", err)
+	}
+	if user.IsDeleted() {
+		return nil, "", errRefreshTokenInvalid()
+	}
+
+	newToken, next, err := newRefreshToken(user.ID, current.FamilyID)
+	if err != nil {
+		return nil, "", apperr.Internal("刷新令牌失败", err)
+	}
+	if err := s.tokens.Rotate(ctx, current, next, now); err != nil {
+		// 令牌在查找之后被并发轮换
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, "", s.refreshTokenReused(ctx, current.FamilyID, now)
+		}
+		return nil, "", apperr.Internal("刷新令牌失败", err)
+	}
+	return user, newToken, nil
+}
+
+func (s *userService) RevokeRefreshToken(ctx context.Context, token string) error {
+	record, err := s.tokens.FindByHash(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil
+		}
+		return apperr.Internal("退出登录失败", err)
+	}
+
+	if err := s.tokens.RevokeFamily(ctx, record.FamilyID, time.Now()); err != nil {
+		return apperr.Internal("退出登录失败", err)
+	}
+	return nil
+}
+
+// refreshTokenReused 已轮换或已吊销的令牌被再次使用，说明令牌可能已泄露，吊销整个令牌族
+func (s *userService) refreshTokenReused(ctx context.Context, familyID string, at time.Time) error {
+	if err := s.tokens.RevokeFamily(ctx, familyID, at); err != nil {
+		slog.ErrorContext(ctx, "failed to revoke refresh token family", "error", err)
+	}
+	return apperr.Unauthorized(apperr.CodeRefreshTokenReused, "刷新令牌已失效，请重新登录")
+}
+
+func errRefreshTokenInvalid() *apperr.Error {
+	return apperr.Unauthorized(apperr.CodeRefreshTokenInvalid, "刷新令牌无效或已过期")
+}
+
+// newRefreshToken 生成刷新令牌，返回明文令牌和待保存的记录（只含令牌哈希）
+func newRefreshToken(userID uint, familyID string) (string, *models.RefreshToken, error) {
+	token, err := randomToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: hashToken(token),
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}, nil
+}
+
+// randomToken 生成 size 字节的随机令牌，以 URL 安全的 base64 编码返回
+func randomToken(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// hashToken 令牌只以 SHA-256 哈希保存，数据库泄露时无法直接使用
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/search"
+)
+
+const (
+	maxSearchHits     = 1000
+	searchSnippetSize = 120
+)
+
+// SearchResult 搜索结果，title 和 snippet 中命中的关键字以 <mark> 标记，其余内容已做 HTML 转义
+type SearchResult struct {
+	Type    string       `json:"type"`
+	ID      uint         `json:"id"`
+	PostID  uint         `json:"post_id"`
+	Score   float64      `json:"score"`
+	Title   string       `json:"title"`
+	Snippet string       `json:"snippet"`
+	Post    *models.Post `json:"post"`
+}
+
+func (s *postService) Search(ctx context.Context, q string, kinds ...string) ([]SearchResult, error) {
+	hits := search.Default.Search(q, kinds...)
+	if len(hits) > maxSearchHits {
+		hits = hits[:maxSearchHits]
+	}
+
+	results, err := s.visibleSearchResults(ctx, hits, q)
+	if err != nil {
+		return nil, apperr.Internal("搜索失败", err)
+	}
+	return results, nil
+}
+
+// visibleSearchResults 用数据库校验命中结果，过滤掉已删除、未发布或未审核的内容
+func (s *postService) visibleSearchResults(ctx context.Context, hits []search.Hit, q string) ([]SearchResult, error) {
+	var commentIDs []uint
+	postIDs := make(map[uint]bool)
+	for _, hit := range hits {
+		if hit.Kind == search.KindComment {
+			commentIDs = append(commentIDs, hit.ID)
+		}
+		postIDs[hit.PostID] = true
+	}
+
+	ids, err := s.comments.ApprovedIDs(ctx, commentIDs)
+	if err != nil {
+		return nil, err
+	}
+	approved := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		approved[id] = true
+	}
+
+	ids = make([]uint, 0, len(postIDs))
+	for id := range postIDs {
+		ids = append(ids, id)
+	}
+	posts, err := s.posts.FindPublished(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	published := make(map[uint]*models.Post, len(posts))
+	for i := range posts {
+		published[posts[i].ID] = &posts[i]
+	}
+
+	results := make([]SearchResult, 0, len(hits))
+	for _, hit := range hits {
+		post, ok := published[hit.PostID]
+		if !ok || (hit.Kind == search.KindComment && !approved[hit.ID]) {
+			continue
+		}
+		results = append(results, SearchResult{
+			Type:    hit.Kind,
+			ID:      hit.ID,
+			PostID:  hit.PostID,
+			Score:   hit.Score,
+			Title:   search.Highlight(post.Title, q),
+			Snippet: search.Snippet(hit.Body, q, searchSnippetSize),
+			Post:    post,
+		})
+	}
+	return results, nil
+}
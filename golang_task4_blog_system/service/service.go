@@ -0,0 +1,15 @@
+// Package service 实现文章、评论和用户的业务规则，数据通过 repository 中的接口读写，
+// 返回给处理函数的错误均为 *apperr.Error
+package service
+
+import "golang_task4_blog_system/models"
+
+// authorized 检查 actor 能否对某资源执行操作，匿名用户（nil）一律拒绝
+func authorized(actor *models.User, ownerID uint, action string) bool {
+	return actor != nil && actor.CanAccess(ownerID, action)
+}
+
+// canViewPost 已发布的文章所有人可见，其他状态仅作者和拥有 posts:update:any 权限的用户可见
+func canViewPost(actor *models.User, post *models.Post) bool {
+	return post.IsPublished() || authorized(actor, post.UserID, "posts:update")
+}
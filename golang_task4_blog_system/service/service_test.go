@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/database"
+	"golang_task4_blog_system/migrations"
+	"golang_task4_blog_system/models"
+
+	"gorm.io/gorm"
+)
+
+// newTestDB 打开执行过全部迁移的 SQLite 内存数据库，测试结束时关闭
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := database.Open(&database.Config{Driver: database.DriverSQLite, Database: ":memory:"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := migrations.Up(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testUser 创建邮箱已验证的用户
+func testUser(id uint, role string) *models.User {
+	verified := time.Now()
+	return &models.User{ID: id, Role: role, EmailVerifiedAt: &verified}
+}
+
+func TestCanViewPost(t *testing.T) {
+	const authorID = 1
+	unverified := &models.User{ID: authorID, Role: models.RoleAuthor}
+
+	tests := []struct {
+		name   string
+		actor  *models.User
+		status string
+		want   bool
+	}{
+		{"anonymous sees published", nil, models.PostStatusPublished, true},
+		{"anonymous cannot see draft", nil, models.PostStatusDraft, false},
+		{"anonymous cannot see scheduled", nil, models.PostStatusScheduled, false},
+		{"reader cannot see archived", testUser(2, models.RoleReader), models.PostStatusArchived, false},
+		{"other author cannot see draft", testUser(2, models.RoleAuthor), models.PostStatusDraft, false},
+		{"editor cannot see draft", testUser(2, models.RoleEditor), models.PostStatusDraft, false},
+		{"author sees own draft", testUser(authorID, models.RoleAuthor), models.PostStatusDraft, true},
+		{"author sees own scheduled", testUser(authorID, models.RoleAuthor), models.PostStatusScheduled, true},
+		{"unverified author cannot see own draft", unverified, models.PostStatusDraft, false},
+		{"admin sees any draft", testUser(2, models.RoleAdmin), models.PostStatusDraft, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			post := &models.Post{UserID: authorID, Status: tt.status}
+			if got := canViewPost(tt.actor, post); got != tt.want {
+				t.Errorf("canViewPost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
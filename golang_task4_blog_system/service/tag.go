@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"golang_task4_blog_system/apperr"
+)
+
+// tagCloudLevels 标签云的字号等级数
+const tagCloudLevels = 5
+
+// TagCloudItem 标签云条目，PostCount 为已发布文章数，Weight 为 1~5 的字号等级
+type TagCloudItem struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	Slug      string `json:"slug"`
+	PostCount int64  `json:"count"`
+	Weight    int    `json:"weight"`
+}
+
+func (s *postService) TagCloud(ctx context.Context, limit int, byName bool) ([]TagCloudItem, error) {
+	counts, err := s.posts.TagCloud(ctx, limit, byName)
+	if err != nil {
+		return nil, apperr.Internal("获取标签失败", err)
+	}
+
+	tags := make([]TagCloudItem, len(counts))
+	for i, t := range counts {
+		tags[i] = TagCloudItem{ID: t.ID, Name: t.Name, Slug: t.Slug, PostCount: t.PostCount}
+	}
+	weighTags(tags)
+	return tags, nil
+}
+
+// weighTags 按文章数在最小值和最大值之间线性划分字号等级
+func weighTags(tags []TagCloudItem) {
+	if len(tags) == 0 {
+		return
+	}
+	lo, hi := tags[0].PostCount, tags[0].PostCount
+	for _, t := range tags {
+		lo, hi = min(lo, t.PostCount), max(hi, t.PostCount)
+	}
+	for i := range tags {
+		if hi == lo {
+			tags[i].Weight = 1
+			continue
+		}
+		tags[i].Weight = 1 + int((tags[i].PostCount-lo)*(tagCloudLevels-1)/(hi-lo))
+	}
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+	"golang_task4_blog_system/ratelimit"
+	"golang_task4_blog_system/repository"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// UserService 用户注册、登录校验、刷新令牌和角色管理
+type UserService interface {
+	Register(ctx context.Context, username, email, password string) (*models.User, error)
+	// Authenticate 校验用户名和密码，成功时返回用户
+	Authenticate(ctx context.Context, username, password string) (*models.User, error)
+	// GetByID 按ID查找用户，用于认证中间件加载访问令牌对应的用户
+	GetByID(ctx context.Context, id uint) (*models.User, error)
+	// IssueRefreshToken 登录成功后为用户签发新令牌族的刷新令牌，返回明文令牌
+	IssueRefreshToken(ctx context.Context, user *models.User) (string, error)
+	// RefreshToken 校验刷新令牌并轮换出新令牌，返回令牌所属用户和新的明文令牌。
+	// 已轮换或已吊销的令牌被再次使用时吊销整个令牌族
+	RefreshToken(ctx context.Context, token string) (*models.User, string, error)
+	// RevokeRefreshToken 吊销令牌所在的整个令牌族，未知令牌直接忽略
+	RevokeRefreshToken(ctx context.Context, token string) error
+	UpdateRole(ctx context.Context, id uint, role string) (*models.User, error)
+	// GetByUsername 按用户名查找用户，用于展示公开资料
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	UpdateProfile(ctx context.Context, actor *models.User, input ProfileInput) (*models.User, error)
+	// VerifyPassword 校验当前用户的密码，用于修改密码和邮箱前的身份确认，失败次数与登录合并计算
+	VerifyPassword(ctx context.Context, actor *models.User, password string) error
+	// ChangePassword 校验当前密码后设置新密码，并让该用户已签发的刷新令牌全部失效
+	ChangePassword(ctx context.Context, actor *models.User, current, password string) error
+	// DeleteAccount 校验密码后注销当前用户，见 repository.UserRepository.Anonymize
+	DeleteAccount(ctx context.Context, actor *models.User, password string) error
+}
+
+// ProfileInput 修改个人资料的输入，nil 表示不修改，空字符串表示清空
+type ProfileInput struct {
+	DisplayName *string
+	Bio         *string
+	AvatarURL   *string
+}
+
+type userService struct {
+	users   repository.UserRepository
+	tokens  repository.RefreshTokenRepository
+	lockout *ratelimit.Lockout
+}
+
+// NewUserService 创建用户服务，lockout 为 nil 时登录失败不锁定账户
+func NewUserService(users repository.UserRepository, tokens repository.RefreshTokenRepository, lockout *ratelimit.Lockout) UserService {
+	return &userService{users: users, tokens: tokens, lockout: lockout}
+}
+
+func (s *userService) Register(ctx context.Context, username, email, password string) (*models.User, error) {
+	if models.IsReservedName(username) {
+		return nil, apperr.Validation(apperr.CodeValidationFailed, "输入验证失败").
+			WithFields(apperr.Field("username", "reserved", "该用户名不可使用"))
+	}
+
+	// 用户名和邮箱不能与已有用户重复
+	existing, err := s.users.FindConflicts(ctx, username, email)
+	if err != nil {
+		return nil, apperr.Internal("创建用户失败", err)
+	}
+	if len(existing) > 0 {
+		conflict := apperr.Conflict(apperr.CodeUserExists, "用户名或邮箱已被使用")
+		for _, u := range existing {
+			if u.Username == username {
+				conflict.WithFields(apperr.Field("username", "taken", "用户名已被使用"))
+			}
+			if u.Email == email {
+				conflict.WithFields(apperr.Field("email", "taken", "邮箱已被注册"))
+			}
+		}
+		return nil, conflict
+	}
+
+	user := models.User{
+		Username: username,
+		Email:    email,
+		Password: password,
+	}
+
+	// 加密密码
+	if err := user.HashPassword(); err != nil {
+		return nil, apperr.Internal("密码加密失败", err)
+	}
+
+	// 创建用户，并发注册时由唯一索引兜底
+	if err := s.users.Create(ctx, &user); err != nil {
+		if errors.Is(err, repository.ErrDuplicated) {
+			return nil, apperr.Conflict(apperr.CodeUserExists, "用户名或邮箱已被使用")
+		}
+		return nil, apperr.Internal("创建用户失败", err)
+	}
+	return &user, nil
+}
+
+func (s *userService) Authenticate(ctx context.Context, username, password string) (*models.User, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		return nil, apperr.Internal("查找用户失败", err)
+	}
+
+	// 不存在的用户名同样计入失败次数
+	ok, locked := s.lockout.Attempt(ctx, username, func() bool {
+		return user != nil && user.CheckPassword(password)
+	})
+	if locked > 0 {
+		return nil, errAccountLocked(locked)
+	}
+	// 用户名不存在和密码错误返回相同的错误，避免据此探测已注册的用户名
+	if !ok {
+		return nil, apperr.Unauthorized(apperr.CodeInvalidCredentials, "用户名或密码错误")
+	}
+	return user, nil
+}
+
+// errAccountLocked 连续登录失败导致账户被临时锁定
+func errAccountLocked(retryAfter time.Duration) *apperr.Error {
+	return apperr.TooManyRequests(apperr.CodeAccountLocked, "登录失败次数过多，账户已临时锁定", retryAfter)
+}
+
+func (s *userService) UpdateRole(ctx context.Context, id uint, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, apperr.Validation(apperr.CodeInvalidRole, "无效的角色").
+			WithFields(apperr.Field("role", "oneof", "无效的角色"))
+	}
+
+	user, err := s.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.users.UpdateRole(ctx, user, role); err != nil {
+		return nil, apperr.Internal("修改角色失败", err)
+	}
+	return user, nil
+}
+
+func (s *userService) GetByID(ctx context.Context, id uint) (*models.User, error) {
+	user, err := s.users.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperr.NotFound(apperr.CodeUserNotFound, "用户不存在")
+		}
+		return nil, apperr.Internal("查找用户失败", err)
+	}
+	return user, nil
+}
+
+func (s *userService) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	user, err := s.users.FindByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, repository.ErrNotFound) {
+			return nil, apperr.NotFound(apperr.CodeUserNotFound, "用户不存在")
+		}
+		return nil, apperr.Internal("查找用户失败", err)
+	}
+	return user, nil
+}
+
+func (s *userService) UpdateProfile(ctx context.Context, actor *models.User, input ProfileInput) (*models.User, error) {
+	fields := map[string]interface{}{}
+	set := func(column string, value *string) {
+		if value != nil {
+			fields[column] = strings.TrimSpace(*value)
+		}
+	}
+	set("display_name", input.DisplayName)
+	set("bio", input.Bio)
+	set("avatar_url", input.AvatarURL)
+	if name, _ := fields["display_name"].(string); models.IsReservedName(name) {
+		return nil, apperr.Validation(apperr.CodeValidationFailed, "输入验证失败").
+			WithFields(apperr.Field("display_name", "reserved", "该名称不可使用"))
+	}
+	if avatar, _ := fields["avatar_url"].(string); avatar != "" && !isHTTPURL(avatar) {
+		return nil, apperr.Validation(apperr.CodeValidationFailed, "输入验证失败").
+			WithFields(apperr.Field("avatar_url", "http_url", "avatar_url 不是有效的 http(s) 地址"))
+	}
+
+	if err := s.users.UpdateProfile(ctx, actor, fields); err != nil {
+		return nil, apperr.Internal("修改资料失败", err)
+	}
+	return actor, nil
+}
+
+// isHTTPURL 是否为 http 或 https 的绝对地址，头像地址会直接展示在页面上，不允许其他协议
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func (s *userService) VerifyPassword(ctx context.Context, actor *models.User, password string) error {
+	ok, locked := s.lockout.Attempt(ctx, actor.Username, func() bool {
+		return actor.CheckPassword(password)
+	})
+	if locked > 0 {
+		return errAccountLocked(locked)
+	}
+	if !ok {
+		return apperr.Validation(apperr.CodeCurrentPasswordIncorrect, "当前密码错误").
+			WithFields(apperr.Field("current_password", "incorrect", "当前密码错误"))
+	}
+	return nil
+}
+
+func (s *userService) ChangePassword(ctx context.Context, actor *models.User, current, password string) error {
+	if err := s.VerifyPassword(ctx, actor, current); err != nil {
+		return err
+	}
+
+	user := models.User{Password: password}
+	if err := user.HashPassword(); err != nil {
+		return apperr.Internal("密码加密失败", err)
+	}
+	if err := s.users.UpdatePassword(ctx, actor, user.Password); err != nil {
+		return apperr.Internal("修改密码失败", err)
+	}
+	return nil
+}
+
+func (s *userService) DeleteAccount(ctx context.Context, actor *models.User, password string) error {
+	if err := s.VerifyPassword(ctx, actor, password); err != nil {
+		return err
+	}
+
+	if err := s.users.Anonymize(ctx, actor, time.Now()); err != nil {
+		return apperr.Internal("注销账户失败", err)
+	}
+	slog.InfoContext(ctx, "account deleted", "user_id", actor.ID)
+	return nil
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang_task4_blog_system/apperr"
+	"golang_task4_blog_system/models"
+)
+
+// errCode 取出领域错误的错误码
+func errCode(err error) string {
+	var e *apperr.Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+	return ""
+}
+
+func TestChangePassword(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	refresh, err := f.users.IssueRefreshToken(ctx, f.alice)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = f.users.ChangePassword(ctx, f.alice, "wrong", "newsecret456")
+	if errCode(err) != apperr.CodeCurrentPasswordIncorrect {
+		t.Fatalf("wrong current password: err = %v", err)
+	}
+	if !f.reload(t).CheckPassword("secret123") {
+		t.Error("password changed despite wrong current password")
+	}
+	// 校验失败不影响已登录的会话
+	if _, refresh, err = f.users.RefreshToken(ctx, refresh); err != nil {
+		t.Fatalf("refresh after failed change: %v", err)
+	}
+
+	if err := f.users.ChangePassword(ctx, f.alice, "secret123", "newsecret456"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.users.Authenticate(ctx, "alice", "newsecret456"); err != nil {
+		t.Errorf("login with new password: %v", err)
+	}
+	if _, err := f.users.Authenticate(ctx, "alice", "secret123"); err == nil {
+		t.Error("old password still accepted")
+	}
+	if _, _, err := f.users.RefreshToken(ctx, refresh); err == nil {
+		t.Error("refresh token still usable after password change")
+	}
+}
+
+func TestChangeEmail(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	verifiedAt := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := f.db.Model(f.alice).Update("email_verified_at", verifiedAt).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := f.db.Create(&models.User{Username: "bob", Email: "bob@example.com", Password: "x", Role: models.RoleAuthor}).Error; err != nil {
+		t.Fatal(err)
+	}
+	alice := f.reload(t)
+
+	if err := f.accounts.ChangeEmail(ctx, alice, "alice@example.com"); errCode(err) != apperr.CodeEmailUnchanged {
+		t.Errorf("same email: err = %v", err)
+	}
+	if err := f.accounts.ChangeEmail(ctx, alice, "bob@example.com"); errCode(err) != apperr.CodeUserExists {
+		t.Errorf("taken email: err = %v", err)
+	}
+
+	if err := f.accounts.ChangeEmail(ctx, alice, "alice@new.example.com"); err != nil {
+		t.Fatal(err)
+	}
+	if to := f.mails.messages[len(f.mails.messages)-1].To; to != "alice@new.example.com" {
+		t.Errorf("verification sent to %s, want the new address", to)
+	}
+
+	// 验证前仍使用原邮箱，原邮箱的验证状态不变
+	pending := f.reload(t)
+	if pending.Email != "alice@example.com" || !pending.IsVerified() {
+		t.Errorf("before verification: email %s verified %v", pending.Email, pending.EmailVerifiedAt)
+	}
+
+	// 新邮箱需要通过自己的验证链接确认，确认后重新记录验证时间
+	changed, err := f.accounts.VerifyEmail(ctx, f.mails.lastToken(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed.Email != "alice@new.example.com" || changed.EmailVerifiedAt == nil || !changed.EmailVerifiedAt.After(verifiedAt) {
+		t.Errorf("after verification: email %s verified %v", changed.Email, changed.EmailVerifiedAt)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	ctx := context.Background()
+	f := newAccountFixture(t)
+	str := func(s string) *string { return &s }
+
+	user, err := f.users.UpdateProfile(ctx, f.alice, ProfileInput{
+		DisplayName: str("  Alice  "),
+		Bio:         str("Go developer"),
+		AvatarURL:   str("https://example.com/a.png"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.DisplayName != "Alice" {
+		t.Errorf("display name = %q, want trimmed", user.DisplayName)
+	}
+
+	// nil 字段不修改，空字符串清空
+	if _, err := f.users.UpdateProfile(ctx, f.reload(t), ProfileInput{Bio: str("")}); err != nil {
+		t.Fatal(err)
+	}
+	stored := f.reload(t)
+	if stored.DisplayName != "Alice" || stored.Bio != "" || stored.AvatarURL != "https://example.com/a.png" {
+		t.Errorf("profile = %q %q %q", stored.DisplayName, stored.Bio, stored.AvatarURL)
+	}
+
+	tests := []struct {
+		name  string
+		input ProfileInput
+		field string
+	}{
+		{"reserved display name", ProfileInput{DisplayName: str("deleted-7")}, "display_name"},
+		{"javascript avatar", ProfileInput{AvatarURL: str("javascript:alert(1)")}, "avatar_url"},
+		{"relative avatar", ProfileInput{AvatarURL: str("/a.png")}, "avatar_url"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := f.users.UpdateProfile(ctx, f.reload(t), tt.input)
+			var e *apperr.Error
+			if !errors.As(err, &e) || len(e.Fields) != 1 || e.Fields[0].Field != tt.field {
+				t.Fatalf("err = %v, want field error on %s", err, tt.field)
+			}
+			if f.reload(t).DisplayName != "Alice" {
+				t.Error("rejected update was saved")
+			}
+		})
+	}
+}